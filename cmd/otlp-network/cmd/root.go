@@ -16,6 +16,7 @@ import (
 	"github.com/andrewhowdencom/otlp.network/internal/telemetry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
 )
 
 var cfgFile string
@@ -66,6 +67,76 @@ from the Linux device it is running on.`,
 			}
 		}
 
+		// NetClass Collector
+		if viper.GetBool("collector.netclass.enabled") {
+			c, err := collector.NewNetClass(viper.GetString("collector.netclass.sysfs"))
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// NetQueues Collector
+		if viper.GetBool("collector.netqueues.enabled") {
+			c, err := collector.NewNetQueues(viper.GetString("collector.netqueues.sysfs"))
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// Qdisc Collector
+		if viper.GetBool("collector.qdisc.enabled") {
+			c, err := collector.NewQdisc()
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// Ethtool Collector
+		if viper.GetBool("collector.ethtool.enabled") {
+			c, err := collector.NewEthtool("/proc",
+				collector.WithEthtoolStats(viper.GetStringSlice("collector.ethtool.stats")...),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// EthtoolInfo Collector
+		if viper.GetBool("collector.ethtoolinfo.enabled") {
+			c, err := collector.NewEthtoolInfo("/proc")
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// LinkEvents Collector
+		if viper.GetBool("collector.linkevents.enabled") {
+			c, err := collector.NewLinkEvents()
+			if err != nil {
+				return err
+			}
+			// Subscribing needs rtnetlink multicast, which is unavailable in some
+			// sandboxes; the other collectors still run without it.
+			if err := c.Start(cmd.Context()); err != nil {
+				otel.Handle(fmt.Errorf("linkevents collector disabled: %w", err))
+			}
+		}
+
 		// Wifi Collector
 		if viper.GetBool("collector.wifi.enabled") {
 			c, err := collector.NewWifi("/proc")
@@ -77,6 +148,19 @@ from the Linux device it is running on.`,
 			}
 		}
 
+		// WifiSurvey Collector
+		if viper.GetBool("collector.wifisurvey.enabled") {
+			c, err := collector.NewWifiSurvey(
+				collector.WithWifiSurveyScanInterval(viper.GetDuration("collector.wifisurvey.scan_interval")),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
 		// TCP Collector
 		if viper.GetBool("collector.tcp.enabled") {
 			c, err := collector.NewTCP("/proc")
@@ -88,6 +172,54 @@ from the Linux device it is running on.`,
 			}
 		}
 
+		// TCPExt Collector
+		if viper.GetBool("collector.tcpext.enabled") {
+			c, err := collector.NewTCPExt("/proc")
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// TCPState Collector
+		if viper.GetBool("collector.tcpstate.enabled") {
+			c, err := collector.NewTCPState("/proc",
+				collector.WithTCPStatePorts(viper.GetIntSlice("collector.tcpstate.ports")...),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// TCPInfo Collector
+		if viper.GetBool("collector.tcpinfo.enabled") {
+			c, err := collector.NewTCPInfo(
+				collector.WithTCPInfoInterval(viper.GetDuration("collector.tcpinfo.interval")),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// Listen Collector
+		if viper.GetBool("collector.listen.enabled") {
+			c, err := collector.NewListen()
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
 		// UDP Collector
 		if viper.GetBool("collector.udp.enabled") {
 			c, err := collector.NewUDP("/proc")
@@ -99,9 +231,109 @@ from the Linux device it is running on.`,
 			}
 		}
 
+		// UDPSocket Collector
+		if viper.GetBool("collector.udpsocket.enabled") {
+			c, err := collector.NewUDPSocket("/proc",
+				collector.WithUDPSocketPorts(viper.GetIntSlice("collector.udpsocket.ports")...),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// UnixSocket Collector
+		if viper.GetBool("collector.unixsocket.enabled") {
+			c, err := collector.NewUnixSocket("/proc",
+				collector.WithUnixSocketPaths(viper.GetStringSlice("collector.unixsocket.paths")...),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// IP Collector
+		if viper.GetBool("collector.ip.enabled") {
+			c, err := collector.NewIP("/proc")
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// IPv6 Collector
+		if viper.GetBool("collector.ipv6.enabled") {
+			c, err := collector.NewIPv6("/proc")
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// Route Collector
+		if viper.GetBool("collector.route.enabled") {
+			c, err := collector.NewRoute("/proc")
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
 		// Conntrack Collector
 		if viper.GetBool("collector.conntrack.enabled") {
-			c, err := collector.NewConntrack("/proc")
+			opts := []collector.ConntrackOption{
+				collector.WithConntrackPerCPU(viper.GetBool("collector.conntrack.per_cpu")),
+			}
+			if viper.GetBool("collector.conntrack.breakdown") {
+				opts = append(opts,
+					collector.WithConntrackBreakdown(viper.GetInt("collector.conntrack.breakdown_limit")),
+					collector.WithConntrackBreakdownInterval(viper.GetDuration("collector.conntrack.breakdown_interval")),
+				)
+			}
+			c, err := collector.NewConntrack("/proc", opts...)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// ConntrackFlows Collector
+		if viper.GetBool("collector.conntrackflows.enabled") {
+			c, err := collector.NewConntrackFlows("/proc",
+				collector.WithConntrackFlowsInterval(viper.GetDuration("collector.conntrackflows.interval")),
+				collector.WithConntrackFlowsTopN(viper.GetInt("collector.conntrackflows.top_n")),
+				collector.WithConntrackFlowsLimit(viper.GetInt("collector.conntrackflows.limit")),
+				collector.WithConntrackFlowsPrefix(
+					viper.GetInt("collector.conntrackflows.prefix_ipv4"),
+					viper.GetInt("collector.conntrackflows.prefix_ipv6"),
+				),
+				collector.WithConntrackFlowsByPort(viper.GetBool("collector.conntrackflows.by_port")),
+				collector.WithConntrackFlowsByDirection(viper.GetBool("collector.conntrackflows.by_direction")),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// ConntrackEvents Collector
+		if viper.GetBool("collector.conntrackevents.enabled") {
+			c, err := collector.NewConntrackEvents()
 			if err != nil {
 				return err
 			}
@@ -112,7 +344,20 @@ from the Linux device it is running on.`,
 
 		// Softnet Collector
 		if viper.GetBool("collector.softnet.enabled") {
-			c, err := collector.NewSoftnet("/proc")
+			c, err := collector.NewSoftnet("/proc",
+				collector.WithSoftnetPerCPU(viper.GetBool("collector.softnet.per_cpu")),
+			)
+			if err != nil {
+				return err
+			}
+			if err := c.Start(cmd.Context()); err != nil {
+				return err
+			}
+		}
+
+		// Interrupts Collector
+		if viper.GetBool("collector.interrupts.enabled") {
+			c, err := collector.NewInterrupts("/proc")
 			if err != nil {
 				return err
 			}
@@ -186,11 +431,50 @@ func init() {
 
 	// Collector flags
 	rootCmd.PersistentFlags().Bool("collector.device.enabled", true, "Enable device collector")
+	rootCmd.PersistentFlags().Bool("collector.netclass.enabled", true, "Enable netclass collector")
+	rootCmd.PersistentFlags().String("collector.netclass.sysfs", "/sys", "Mount point of sysfs read by the netclass collector")
+	rootCmd.PersistentFlags().Bool("collector.netqueues.enabled", true, "Enable netqueues collector")
+	rootCmd.PersistentFlags().String("collector.netqueues.sysfs", "/sys", "Mount point of sysfs read by the netqueues collector")
+	rootCmd.PersistentFlags().Bool("collector.qdisc.enabled", true, "Enable qdisc collector")
+	rootCmd.PersistentFlags().Bool("collector.ethtool.enabled", true, "Enable ethtool collector")
+	rootCmd.PersistentFlags().StringSlice("collector.ethtool.stats", nil, "Regular expressions selecting the driver statistics reported by the ethtool collector (all if empty)")
+	rootCmd.PersistentFlags().Bool("collector.ethtoolinfo.enabled", true, "Enable ethtoolinfo collector")
+	rootCmd.PersistentFlags().Bool("collector.linkevents.enabled", true, "Enable linkevents collector")
 	rootCmd.PersistentFlags().Bool("collector.wifi.enabled", true, "Enable wifi collector")
+	rootCmd.PersistentFlags().Bool("collector.wifisurvey.enabled", false, "Enable wifisurvey collector")
+	rootCmd.PersistentFlags().Duration("collector.wifisurvey.scan_interval", 5*time.Minute, "Interval at which the wifisurvey collector triggers scans (0 only reads existing scan results)")
 	rootCmd.PersistentFlags().Bool("collector.tcp.enabled", true, "Enable tcp collector")
+	rootCmd.PersistentFlags().Bool("collector.tcpext.enabled", true, "Enable tcpext collector")
+	rootCmd.PersistentFlags().Bool("collector.tcpstate.enabled", true, "Enable tcpstate collector")
+	rootCmd.PersistentFlags().IntSlice("collector.tcpstate.ports", nil, "Local ports reported individually by the tcpstate collector (others are aggregated)")
+	rootCmd.PersistentFlags().Bool("collector.tcpinfo.enabled", true, "Enable tcpinfo collector")
+	rootCmd.PersistentFlags().Duration("collector.tcpinfo.interval", 60*time.Second, "Interval at which the tcpinfo collector samples connections")
+	rootCmd.PersistentFlags().Bool("collector.listen.enabled", true, "Enable listen collector")
 	rootCmd.PersistentFlags().Bool("collector.udp.enabled", true, "Enable udp collector")
+	rootCmd.PersistentFlags().Bool("collector.udpsocket.enabled", true, "Enable udpsocket collector")
+	rootCmd.PersistentFlags().IntSlice("collector.udpsocket.ports", nil, "Local ports reported individually by the udpsocket collector (others are aggregated)")
+	rootCmd.PersistentFlags().Bool("collector.unixsocket.enabled", true, "Enable unixsocket collector")
+	rootCmd.PersistentFlags().StringSlice("collector.unixsocket.paths", nil, "Path globs of Unix listeners whose accept queues are reported by the unixsocket collector")
+	rootCmd.PersistentFlags().Bool("collector.ip.enabled", true, "Enable ip collector")
+	rootCmd.PersistentFlags().Bool("collector.ipv6.enabled", true, "Enable ipv6 collector")
+	rootCmd.PersistentFlags().Bool("collector.route.enabled", true, "Enable route collector")
 	rootCmd.PersistentFlags().Bool("collector.conntrack.enabled", true, "Enable conntrack collector")
+	rootCmd.PersistentFlags().Bool("collector.conntrack.per_cpu", false, "Report conntrack statistics per CPU instead of summed")
+	rootCmd.PersistentFlags().Bool("collector.conntrack.breakdown", false, "Count conntrack table entries by protocol, TCP state, zone and assured flag")
+	rootCmd.PersistentFlags().Int("collector.conntrack.breakdown_limit", 100000, "Maximum number of conntrack table entries read for the breakdown per sample")
+	rootCmd.PersistentFlags().Duration("collector.conntrack.breakdown_interval", 60*time.Second, "Interval at which the conntrack table is read for the breakdown")
+	rootCmd.PersistentFlags().Bool("collector.conntrackflows.enabled", false, "Enable conntrackflows (top talkers) collector")
+	rootCmd.PersistentFlags().Duration("collector.conntrackflows.interval", 60*time.Second, "Interval at which the conntrackflows collector samples the conntrack table")
+	rootCmd.PersistentFlags().Int("collector.conntrackflows.top_n", 10, "Number of flow aggregates reported by the conntrackflows collector")
+	rootCmd.PersistentFlags().Int("collector.conntrackflows.limit", 100000, "Maximum number of conntrack table entries read per sample")
+	rootCmd.PersistentFlags().Int("collector.conntrackflows.prefix_ipv4", 32, "Prefix length IPv4 addresses are aggregated to (e.g. 24)")
+	rootCmd.PersistentFlags().Int("collector.conntrackflows.prefix_ipv6", 128, "Prefix length IPv6 addresses are aggregated to (e.g. 64)")
+	rootCmd.PersistentFlags().Bool("collector.conntrackflows.by_port", false, "Split flow aggregates by protocol and destination port")
+	rootCmd.PersistentFlags().Bool("collector.conntrackflows.by_direction", false, "Split flow aggregates by direction (original and reply)")
+	rootCmd.PersistentFlags().Bool("collector.conntrackevents.enabled", false, "Enable conntrackevents collector")
 	rootCmd.PersistentFlags().Bool("collector.softnet.enabled", true, "Enable softnet collector")
+	rootCmd.PersistentFlags().Bool("collector.softnet.per_cpu", false, "Report softnet statistics per CPU instead of summed")
+	rootCmd.PersistentFlags().Bool("collector.interrupts.enabled", true, "Enable interrupts collector")
 	rootCmd.PersistentFlags().Bool("collector.sockstat.enabled", true, "Enable sockstat collector")
 
 	viper.BindPFlag("otel.endpoint", rootCmd.PersistentFlags().Lookup("otel.endpoint"))
@@ -200,11 +484,50 @@ func init() {
 	viper.BindPFlag("prometheus.port", rootCmd.PersistentFlags().Lookup("prometheus.port"))
 
 	viper.BindPFlag("collector.device.enabled", rootCmd.PersistentFlags().Lookup("collector.device.enabled"))
+	viper.BindPFlag("collector.netclass.enabled", rootCmd.PersistentFlags().Lookup("collector.netclass.enabled"))
+	viper.BindPFlag("collector.netclass.sysfs", rootCmd.PersistentFlags().Lookup("collector.netclass.sysfs"))
+	viper.BindPFlag("collector.netqueues.enabled", rootCmd.PersistentFlags().Lookup("collector.netqueues.enabled"))
+	viper.BindPFlag("collector.netqueues.sysfs", rootCmd.PersistentFlags().Lookup("collector.netqueues.sysfs"))
+	viper.BindPFlag("collector.qdisc.enabled", rootCmd.PersistentFlags().Lookup("collector.qdisc.enabled"))
+	viper.BindPFlag("collector.ethtool.enabled", rootCmd.PersistentFlags().Lookup("collector.ethtool.enabled"))
+	viper.BindPFlag("collector.ethtool.stats", rootCmd.PersistentFlags().Lookup("collector.ethtool.stats"))
+	viper.BindPFlag("collector.ethtoolinfo.enabled", rootCmd.PersistentFlags().Lookup("collector.ethtoolinfo.enabled"))
+	viper.BindPFlag("collector.linkevents.enabled", rootCmd.PersistentFlags().Lookup("collector.linkevents.enabled"))
 	viper.BindPFlag("collector.wifi.enabled", rootCmd.PersistentFlags().Lookup("collector.wifi.enabled"))
+	viper.BindPFlag("collector.wifisurvey.enabled", rootCmd.PersistentFlags().Lookup("collector.wifisurvey.enabled"))
+	viper.BindPFlag("collector.wifisurvey.scan_interval", rootCmd.PersistentFlags().Lookup("collector.wifisurvey.scan_interval"))
 	viper.BindPFlag("collector.tcp.enabled", rootCmd.PersistentFlags().Lookup("collector.tcp.enabled"))
+	viper.BindPFlag("collector.tcpext.enabled", rootCmd.PersistentFlags().Lookup("collector.tcpext.enabled"))
+	viper.BindPFlag("collector.tcpstate.enabled", rootCmd.PersistentFlags().Lookup("collector.tcpstate.enabled"))
+	viper.BindPFlag("collector.tcpstate.ports", rootCmd.PersistentFlags().Lookup("collector.tcpstate.ports"))
+	viper.BindPFlag("collector.tcpinfo.enabled", rootCmd.PersistentFlags().Lookup("collector.tcpinfo.enabled"))
+	viper.BindPFlag("collector.tcpinfo.interval", rootCmd.PersistentFlags().Lookup("collector.tcpinfo.interval"))
+	viper.BindPFlag("collector.listen.enabled", rootCmd.PersistentFlags().Lookup("collector.listen.enabled"))
 	viper.BindPFlag("collector.udp.enabled", rootCmd.PersistentFlags().Lookup("collector.udp.enabled"))
+	viper.BindPFlag("collector.udpsocket.enabled", rootCmd.PersistentFlags().Lookup("collector.udpsocket.enabled"))
+	viper.BindPFlag("collector.udpsocket.ports", rootCmd.PersistentFlags().Lookup("collector.udpsocket.ports"))
+	viper.BindPFlag("collector.unixsocket.enabled", rootCmd.PersistentFlags().Lookup("collector.unixsocket.enabled"))
+	viper.BindPFlag("collector.unixsocket.paths", rootCmd.PersistentFlags().Lookup("collector.unixsocket.paths"))
+	viper.BindPFlag("collector.ip.enabled", rootCmd.PersistentFlags().Lookup("collector.ip.enabled"))
+	viper.BindPFlag("collector.ipv6.enabled", rootCmd.PersistentFlags().Lookup("collector.ipv6.enabled"))
+	viper.BindPFlag("collector.route.enabled", rootCmd.PersistentFlags().Lookup("collector.route.enabled"))
 	viper.BindPFlag("collector.conntrack.enabled", rootCmd.PersistentFlags().Lookup("collector.conntrack.enabled"))
+	viper.BindPFlag("collector.conntrack.per_cpu", rootCmd.PersistentFlags().Lookup("collector.conntrack.per_cpu"))
+	viper.BindPFlag("collector.conntrack.breakdown", rootCmd.PersistentFlags().Lookup("collector.conntrack.breakdown"))
+	viper.BindPFlag("collector.conntrack.breakdown_limit", rootCmd.PersistentFlags().Lookup("collector.conntrack.breakdown_limit"))
+	viper.BindPFlag("collector.conntrack.breakdown_interval", rootCmd.PersistentFlags().Lookup("collector.conntrack.breakdown_interval"))
+	viper.BindPFlag("collector.conntrackflows.enabled", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.enabled"))
+	viper.BindPFlag("collector.conntrackflows.interval", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.interval"))
+	viper.BindPFlag("collector.conntrackflows.top_n", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.top_n"))
+	viper.BindPFlag("collector.conntrackflows.limit", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.limit"))
+	viper.BindPFlag("collector.conntrackflows.prefix_ipv4", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.prefix_ipv4"))
+	viper.BindPFlag("collector.conntrackflows.prefix_ipv6", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.prefix_ipv6"))
+	viper.BindPFlag("collector.conntrackflows.by_port", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.by_port"))
+	viper.BindPFlag("collector.conntrackflows.by_direction", rootCmd.PersistentFlags().Lookup("collector.conntrackflows.by_direction"))
+	viper.BindPFlag("collector.conntrackevents.enabled", rootCmd.PersistentFlags().Lookup("collector.conntrackevents.enabled"))
 	viper.BindPFlag("collector.softnet.enabled", rootCmd.PersistentFlags().Lookup("collector.softnet.enabled"))
+	viper.BindPFlag("collector.softnet.per_cpu", rootCmd.PersistentFlags().Lookup("collector.softnet.per_cpu"))
+	viper.BindPFlag("collector.interrupts.enabled", rootCmd.PersistentFlags().Lookup("collector.interrupts.enabled"))
 	viper.BindPFlag("collector.sockstat.enabled", rootCmd.PersistentFlags().Lookup("collector.sockstat.enabled"))
 
 	// Cobra also supports local flags, which will only run
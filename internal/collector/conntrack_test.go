@@ -2,12 +2,17 @@ package collector
 
 import (
 	"context"
+	"encoding/binary"
+	"net/netip"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/vishvananda/netlink/nl"
 	"go.opentelemetry.io/otel"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/sys/unix"
 )
 
 func TestConntrack(t *testing.T) {
@@ -75,4 +80,221 @@ func TestConntrack(t *testing.T) {
 	} else {
 		t.Error("conntrack.limit not found")
 	}
+
+	// Fixture: /proc/net/stat/nf_conntrack has CPUs 0 and 1, summed here.
+	for name, want := range map[string]int64{
+		"conntrack.found":          0x30,
+		"conntrack.invalid":        6,
+		"conntrack.insert_failed":  2,
+		"conntrack.drop":           10,
+		"conntrack.early_drop":     1,
+		"conntrack.error":          4,
+		"conntrack.search_restart": 7,
+	} {
+		m := findMetric(name)
+		if m.Name == "" {
+			t.Errorf("%s not found", name)
+			continue
+		}
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("%s is not Sum[int64], got %T", name, m.Data)
+			continue
+		}
+		if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != want {
+			t.Errorf("%s = %v, want %d", name, sum.DataPoints, want)
+		}
+	}
+}
+
+func TestConntrackPerCPU(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	sysPath, _ := filepath.Abs("testdata/sys")
+	c, err := NewConntrack(procPath, WithConntrackPerCPU(true), WithConntrackSysMountPoint(sysPath))
+	if err != nil {
+		t.Fatalf("failed to create conntrack collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+
+	// Check conntrack.drop (Sum) per CPU
+	var found bool
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "conntrack.drop" {
+			continue
+		}
+		found = true
+
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Fatalf("conntrack.drop is not Sum[int64], got %T", m.Data)
+		}
+		// The fixture's possible CPUs are 0 and 2.
+		want := map[string]int64{"0": 3, "2": 7}
+		if len(sum.DataPoints) != len(want) {
+			t.Errorf("expected %d conntrack.drop data points, got %d", len(want), len(sum.DataPoints))
+		}
+		for _, dp := range sum.DataPoints {
+			cpu, _ := dp.Attributes.Value("cpu")
+			if dp.Value != want[cpu.AsString()] {
+				t.Errorf("conntrack.drop cpu %s = %d, want %d", cpu.AsString(), dp.Value, want[cpu.AsString()])
+			}
+		}
+	}
+	if !found {
+		t.Error("conntrack.drop not found")
+	}
+}
+
+func TestReadProcConntrack(t *testing.T) {
+	path, _ := filepath.Abs("testdata/proc/net/nf_conntrack")
+
+	counts := make(map[conntrackEntry]int64)
+	truncated, err := readProcConntrack(path, 100, func(e conntrackEntry) {
+		counts[conntrackEntry{Protocol: e.Protocol, State: e.State, Zone: e.Zone, Assured: e.Assured}]++
+	})
+	if err != nil {
+		t.Fatalf("failed to read conntrack table: %v", err)
+	}
+	if truncated {
+		t.Error("expected the whole table to be read")
+	}
+
+	want := map[conntrackEntry]int64{
+		{Protocol: "tcp", State: "established", Assured: true}: 1,
+		{Protocol: "tcp", State: "syn_sent"}:                   1,
+		{Protocol: "udp", Zone: 2}:                             2,
+		{Protocol: "icmpv6"}:                                   1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("expected %d groups, got %d: %v", len(want), len(counts), counts)
+	}
+	for e, n := range want {
+		if counts[e] != n {
+			t.Errorf("expected %d entries of %+v, got %d", n, e, counts[e])
+		}
+	}
+
+	// The tuples and counters of the first entry.
+	var first conntrackEntry
+	if _, err := readProcConntrack(path, 1, func(e conntrackEntry) { first = e }); err != nil {
+		t.Fatalf("failed to read conntrack table: %v", err)
+	}
+	wantFirst := conntrackEntry{
+		Protocol: "tcp",
+		State:    "established",
+		Assured:  true,
+		Original: conntrackTuple{
+			Source:          netip.MustParseAddr("10.0.0.1"),
+			Destination:     netip.MustParseAddr("10.0.0.2"),
+			SourcePort:      43210,
+			DestinationPort: 22,
+			Packets:         12,
+			Bytes:           1500,
+		},
+		Reply: conntrackTuple{
+			Source:          netip.MustParseAddr("10.0.0.2"),
+			Destination:     netip.MustParseAddr("10.0.0.1"),
+			SourcePort:      22,
+			DestinationPort: 43210,
+			Packets:         10,
+			Bytes:           4200,
+		},
+	}
+	if first != wantFirst {
+		t.Errorf("expected %+v, got %+v", wantFirst, first)
+	}
+
+	// The read stops at the limit.
+	var read int
+	truncated, err = readProcConntrack(path, 3, func(conntrackEntry) { read++ })
+	if err != nil {
+		t.Fatalf("failed to read conntrack table: %v", err)
+	}
+	if !truncated || read != 3 {
+		t.Errorf("expected 3 entries and truncation, got %d (truncated %v)", read, truncated)
+	}
+}
+
+func TestParseConntrackEntry(t *testing.T) {
+	be16 := func(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+	be32 := func(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+	be64 := func(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+	tuple := nl.NewRtAttr(nl.CTA_TUPLE_ORIG|unix.NLA_F_NESTED, nil)
+	ip := tuple.AddRtAttr(nl.CTA_TUPLE_IP|unix.NLA_F_NESTED, nil)
+	ip.AddRtAttr(nl.CTA_IP_V6_SRC, netip.MustParseAddr("2001:db8::1").AsSlice())
+	ip.AddRtAttr(nl.CTA_IP_V6_DST, netip.MustParseAddr("2001:db8::2").AsSlice())
+	proto := tuple.AddRtAttr(nl.CTA_TUPLE_PROTO|unix.NLA_F_NESTED, nil)
+	proto.AddRtAttr(nl.CTA_PROTO_NUM, []byte{unix.IPPROTO_TCP})
+	proto.AddRtAttr(nl.CTA_PROTO_SRC_PORT, be16(50000))
+	proto.AddRtAttr(nl.CTA_PROTO_DST_PORT, be16(443))
+
+	counters := nl.NewRtAttr(nl.CTA_COUNTERS_ORIG|unix.NLA_F_NESTED, nil)
+	counters.AddRtAttr(nl.CTA_COUNTERS_PACKETS, be64(5))
+	counters.AddRtAttr(nl.CTA_COUNTERS_BYTES, be64(600))
+
+	info := nl.NewRtAttr(nl.CTA_PROTOINFO|unix.NLA_F_NESTED, nil)
+	tcp := info.AddRtAttr(nl.CTA_PROTOINFO_TCP|unix.NLA_F_NESTED, nil)
+	tcp.AddRtAttr(nl.CTA_PROTOINFO_TCP_STATE, []byte{7})
+
+	timestamp := nl.NewRtAttr(nl.CTA_TIMESTAMP|unix.NLA_F_NESTED, nil)
+	timestamp.AddRtAttr(nl.CTA_TIMESTAMP_START, be64(1700000000e9))
+
+	var b []byte
+	for _, attr := range []*nl.RtAttr{
+		tuple,
+		counters,
+		info,
+		nl.NewRtAttr(nl.CTA_STATUS, be32(ipsAssured|1<<3)), // assured, confirmed
+		nl.NewRtAttr(nl.CTA_ZONE, be16(4)),
+		nl.NewRtAttr(nl.CTA_ID, be32(42)),
+		timestamp,
+	} {
+		b = append(b, attr.Serialize()...)
+	}
+
+	a, err := parseNLAttrs(b)
+	if err != nil {
+		t.Fatalf("failed to parse attributes: %v", err)
+	}
+	e, err := parseConntrackEntry(a)
+	if err != nil {
+		t.Fatalf("failed to parse entry: %v", err)
+	}
+
+	want := conntrackEntry{
+		Protocol: "tcp",
+		State:    "time_wait",
+		Zone:     4,
+		Assured:  true,
+		ID:       42,
+		Start:    time.Unix(1700000000, 0),
+		Original: conntrackTuple{
+			Source:          netip.MustParseAddr("2001:db8::1"),
+			Destination:     netip.MustParseAddr("2001:db8::2"),
+			SourcePort:      50000,
+			DestinationPort: 443,
+			Packets:         5,
+			Bytes:           600,
+		},
+	}
+	if e != want {
+		t.Errorf("expected %+v, got %+v", want, e)
+	}
 }
@@ -0,0 +1,262 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// conntrackEventsBufferSize is the receive buffer requested for the event socket, so
+	// bursts of connections do not overflow it.
+	conntrackEventsBufferSize = 4 << 20
+
+	// conntrackEventsTracked bounds the number of connections whose creation time is kept
+	// to compute their lifetime.
+	conntrackEventsTracked = 1 << 17
+
+	// conntrackEventsMinBackoff and conntrackEventsMaxBackoff bound the delay before
+	// subscribing again after receiving events failed.
+	conntrackEventsMinBackoff = time.Second
+	conntrackEventsMaxBackoff = 5 * time.Minute
+)
+
+// ConntrackEvents collector counts the connections created and destroyed, and records the
+// lifetime and traffic of each connection when it is destroyed, from the ctnetlink
+// NEW and DESTROY multicast events.
+//
+// Unlike the table size sampled by the Conntrack collector, this captures connections that
+// are created and destroyed between collections.
+type ConntrackEvents struct {
+	meter metric.Meter
+	// started holds when connections were created, by conntrack ID. It is only used when the
+	// kernel does not timestamp connections (net.netfilter.nf_conntrack_timestamp).
+	started map[uint32]time.Time
+
+	created   metric.Int64Counter
+	destroyed metric.Int64Counter
+	overflows metric.Int64Counter
+	failures  metric.Int64Counter
+	duration  metric.Float64Histogram
+	bytes     metric.Int64Histogram
+}
+
+// NewConntrackEvents creates a new ConntrackEvents collector.
+func NewConntrackEvents() (*ConntrackEvents, error) {
+	return &ConntrackEvents{
+		meter:   otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		started: make(map[uint32]time.Time),
+	}, nil
+}
+
+// Start subscribes to the NFNLGRP_CONNTRACK_NEW and NFNLGRP_CONNTRACK_DESTROY multicast
+// groups, handling the events until ctx is cancelled.
+func (c *ConntrackEvents) Start(ctx context.Context) error {
+	if err := c.createInstruments(); err != nil {
+		return err
+	}
+
+	s, err := subscribeConntrackEvents()
+	if err != nil {
+		return err
+	}
+
+	go c.run(ctx, s)
+	return nil
+}
+
+// subscribeConntrackEvents opens a socket subscribed to the conntrack events.
+func subscribeConntrackEvents() (*nl.NetlinkSocket, error) {
+	s, err := nl.Subscribe(unix.NETLINK_NETFILTER, unix.NFNLGRP_CONNTRACK_NEW, unix.NFNLGRP_CONNTRACK_DESTROY)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to conntrack events: %w", err)
+	}
+	// Raising the buffer above net.core.rmem_max needs CAP_NET_ADMIN, which subscribing
+	// already does; the default buffer is used if neither succeeds.
+	if err := s.SetReceiveBufferSize(conntrackEventsBufferSize, true); err != nil {
+		_ = s.SetReceiveBufferSize(conntrackEventsBufferSize, false)
+	}
+	return s, nil
+}
+
+// run handles the events of s until ctx is cancelled. When receiving fails, the socket is
+// replaced by a new subscription, retried with an exponential backoff.
+func (c *ConntrackEvents) run(ctx context.Context, s *nl.NetlinkSocket) {
+	backoff := conntrackEventsMinBackoff
+	for {
+		subscribed := time.Now()
+		err := c.receive(ctx, s)
+		s.Close()
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A subscription that worked for a while starts the backoff over.
+		if time.Since(subscribed) > conntrackEventsMaxBackoff {
+			backoff = conntrackEventsMinBackoff
+		}
+		// Destroy events may be lost until the new subscription, so the creation times kept
+		// are no longer reliable.
+		clear(c.started)
+
+		for {
+			c.failures.Add(ctx, 1)
+			otel.Handle(fmt.Errorf("conntrack events unavailable, resubscribing in %s: %w", backoff, err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, conntrackEventsMaxBackoff)
+
+			if s, err = subscribeConntrackEvents(); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// receive handles the events of s until receiving fails or ctx is cancelled. Overflows of
+// the receive buffer are counted and do not stop it.
+func (c *ConntrackEvents) receive(ctx context.Context, s *nl.NetlinkSocket) error {
+	// Closing the socket interrupts the blocked Receive below.
+	stop := context.AfterFunc(ctx, s.Close)
+	defer stop()
+
+	for {
+		msgs, _, err := s.Receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The kernel dropped events because the buffer was full. Destroy events may
+			// have been lost, so the creation times kept are no longer reliable.
+			if errors.Is(err, unix.ENOBUFS) {
+				c.overflows.Add(ctx, 1)
+				clear(c.started)
+				continue
+			}
+			return fmt.Errorf("failed to receive conntrack events: %w", err)
+		}
+
+		now := time.Now()
+		for _, m := range msgs {
+			if m.Header.Type>>8 != unix.NFNL_SUBSYS_CTNETLINK || len(m.Data) < nl.SizeofNfgenmsg {
+				continue
+			}
+			a, err := parseNLAttrs(m.Data[nl.SizeofNfgenmsg:])
+			if err != nil {
+				otel.Handle(err)
+				continue
+			}
+			e, err := parseConntrackEntry(a)
+			if err != nil {
+				continue
+			}
+			c.handle(ctx, m.Header.Type&0xff, e, now)
+		}
+	}
+}
+
+// createInstruments creates the ConntrackEvents counters and histograms.
+func (c *ConntrackEvents) createInstruments() error {
+	var err error
+	c.created, err = c.meter.Int64Counter(
+		"conntrack.connections.created",
+		metric.WithDescription("Connections added to the conntrack table"),
+		metric.WithUnit("{connections}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.destroyed, err = c.meter.Int64Counter(
+		"conntrack.connections.destroyed",
+		metric.WithDescription("Connections removed from the conntrack table"),
+		metric.WithUnit("{connections}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.overflows, err = c.meter.Int64Counter(
+		"conntrack.events.overflows",
+		metric.WithDescription("Times conntrack events were lost because the receive buffer was full"),
+		metric.WithUnit("{overflows}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.failures, err = c.meter.Int64Counter(
+		"conntrack.events.failures",
+		metric.WithDescription("Failures to receive conntrack events or to subscribe to them again"),
+		metric.WithUnit("{failures}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.duration, err = c.meter.Float64Histogram(
+		"conntrack.connection.duration",
+		metric.WithDescription("Lifetime of connections removed from the conntrack table"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600, 21600, 86400),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.bytes, err = c.meter.Int64Histogram(
+		"conntrack.connection.bytes",
+		metric.WithDescription("Bytes transferred in both directions by connections removed from the conntrack table"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10),
+	)
+	return err
+}
+
+// handle counts a NEW or DELETE (destroy) event, recording the lifetime and traffic of
+// destroyed connections.
+func (c *ConntrackEvents) handle(ctx context.Context, msgType uint16, e conntrackEntry, now time.Time) {
+	attrs := metric.WithAttributes(attribute.String("protocol", e.Protocol))
+
+	switch msgType {
+	case nl.IPCTNL_MSG_CT_NEW:
+		c.created.Add(ctx, 1, attrs)
+		if e.Start.IsZero() && len(c.started) < conntrackEventsTracked {
+			c.started[e.ID] = now
+		}
+
+	case nl.IPCTNL_MSG_CT_DELETE:
+		c.destroyed.Add(ctx, 1, attrs)
+
+		// The lifetime of connections created before the subscription is only known from
+		// the kernel's timestamps.
+		start, ok := c.started[e.ID]
+		delete(c.started, e.ID)
+		if !e.Start.IsZero() {
+			start, ok = e.Start, true
+			if !e.Stop.IsZero() {
+				now = e.Stop
+			}
+		}
+		if ok {
+			c.duration.Record(ctx, now.Sub(start).Seconds(), attrs)
+		}
+
+		// Every connection is created by a packet, so no packets means nf_conntrack_acct
+		// is disabled.
+		if e.Original.Packets+e.Reply.Packets > 0 {
+			c.bytes.Record(ctx, int64(e.Original.Bytes+e.Reply.Bytes), attrs)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestConntrackEvents(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	c, err := NewConntrackEvents()
+	if err != nil {
+		t.Fatalf("failed to create conntrackevents collector: %v", err)
+	}
+
+	// Drive the handler directly rather than through a subscription, which needs
+	// CAP_NET_ADMIN.
+	if err := c.createInstruments(); err != nil {
+		t.Fatalf("failed to create instruments: %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	// A TCP connection seen being created, lasting 30s and transferring 6000 bytes.
+	c.handle(ctx, nl.IPCTNL_MSG_CT_NEW, conntrackEntry{Protocol: "tcp", ID: 1}, now)
+	c.handle(ctx, nl.IPCTNL_MSG_CT_DELETE, conntrackEntry{
+		Protocol: "tcp",
+		ID:       1,
+		Original: conntrackTuple{Packets: 10, Bytes: 1000},
+		Reply:    conntrackTuple{Packets: 8, Bytes: 5000},
+	}, now.Add(30*time.Second))
+
+	// A UDP connection created before the subscription, timestamped by the kernel.
+	c.handle(ctx, nl.IPCTNL_MSG_CT_DELETE, conntrackEntry{
+		Protocol: "udp",
+		ID:       2,
+		Start:    now.Add(-2 * time.Second),
+		Stop:     now,
+	}, now.Add(time.Second))
+
+	// A UDP connection created before the subscription, without timestamps: its lifetime
+	// is unknown.
+	c.handle(ctx, nl.IPCTNL_MSG_CT_DELETE, conntrackEntry{Protocol: "udp", ID: 3}, now)
+
+	if len(c.started) != 0 {
+		t.Errorf("expected no tracked connections, got %d", len(c.started))
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+
+	counts := map[string]map[string]int64{}
+	durations := map[string]float64{}
+	var bytes []metricdata.HistogramDataPoint[int64]
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			counts[m.Name] = map[string]int64{}
+			for _, dp := range data.DataPoints {
+				protocol, _ := dp.Attributes.Value("protocol")
+				counts[m.Name][protocol.AsString()] = dp.Value
+			}
+		case metricdata.Histogram[float64]:
+			for _, dp := range data.DataPoints {
+				protocol, _ := dp.Attributes.Value("protocol")
+				if dp.Count != 1 {
+					t.Errorf("expected 1 %s lifetime, got %d", protocol.AsString(), dp.Count)
+				}
+				durations[protocol.AsString()] = dp.Sum
+			}
+		case metricdata.Histogram[int64]:
+			bytes = data.DataPoints
+		}
+	}
+
+	if got := counts["conntrack.connections.created"]; len(got) != 1 || got["tcp"] != 1 {
+		t.Errorf("expected 1 tcp connection created, got %v", got)
+	}
+	if got := counts["conntrack.connections.destroyed"]; got["tcp"] != 1 || got["udp"] != 2 {
+		t.Errorf("expected 1 tcp and 2 udp connections destroyed, got %v", got)
+	}
+	if durations["tcp"] != 30 || durations["udp"] != 2 {
+		t.Errorf("expected lifetimes of 30s (tcp) and 2s (udp), got %v", durations)
+	}
+
+	// Only the connection with counters has its traffic recorded.
+	if len(bytes) != 1 || bytes[0].Count != 1 || bytes[0].Sum != 6000 {
+		t.Errorf("expected a single connection of 6000 bytes, got %+v", bytes)
+	}
+}
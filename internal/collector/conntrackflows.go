@@ -0,0 +1,413 @@
+package collector
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// conntrackFlowsOther is the attribute value of the aggregate of the flows outside the top N.
+const conntrackFlowsOther = "other"
+
+// ConntrackFlows collector exposes the traffic of the connections with the most bytes
+// ("top talkers"), from the byte and packet counters of conntrack entries
+// (net.netfilter.nf_conntrack_acct).
+//
+// Counters only grow while a connection exists, so entries are sampled on an interval and
+// the traffic between samples is aggregated (by address pair, subnet, port and direction)
+// and reported for the top N aggregates; the rest are summed as "other".
+type ConntrackFlows struct {
+	meter          metric.Meter
+	procMountPoint string
+	interval       time.Duration
+	topN           int
+	limit          int
+	prefixIPv4     int
+	prefixIPv6     int
+	byPort         bool
+	byDirection    bool
+
+	// previous holds the counters of every flow at the last sample.
+	previous map[conntrackFlowID]conntrackFlowCounters
+	// seeded is set once the first sample has been taken.
+	seeded bool
+	// sampledAt is when the last sample was taken, and truncated whether it read fewer
+	// entries than the table had.
+	sampledAt time.Time
+	truncated bool
+
+	mu sync.Mutex
+	// top holds the aggregates of the last interval, observed by the gauges.
+	top []conntrackFlowTotal
+}
+
+// ConntrackFlowsOption configures the ConntrackFlows collector.
+type ConntrackFlowsOption func(*ConntrackFlows) error
+
+// WithConntrackFlowsInterval sets how often the conntrack table is sampled (defaults to 60s).
+func WithConntrackFlowsInterval(d time.Duration) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		if d <= 0 {
+			return errors.New("interval must be positive")
+		}
+		c.interval = d
+		return nil
+	}
+}
+
+// WithConntrackFlowsTopN sets the number of aggregates reported (defaults to 10).
+func WithConntrackFlowsTopN(n int) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		if n <= 0 {
+			return errors.New("top n must be positive")
+		}
+		c.topN = n
+		return nil
+	}
+}
+
+// WithConntrackFlowsLimit sets the number of conntrack entries read per sample (defaults
+// to 100000).
+func WithConntrackFlowsLimit(limit int) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		if limit <= 0 {
+			return errors.New("limit must be positive")
+		}
+		c.limit = limit
+		return nil
+	}
+}
+
+// WithConntrackFlowsPrefix aggregates addresses into subnets of the given prefix lengths,
+// e.g. 24 and 64. The defaults, 32 and 128, report individual addresses.
+func WithConntrackFlowsPrefix(ipv4, ipv6 int) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		if ipv4 < 0 || ipv4 > 32 || ipv6 < 0 || ipv6 > 128 {
+			return fmt.Errorf("invalid prefix lengths /%d and /%d", ipv4, ipv6)
+		}
+		c.prefixIPv4, c.prefixIPv6 = ipv4, ipv6
+		return nil
+	}
+}
+
+// WithConntrackFlowsByPort splits the aggregates by protocol and destination port.
+func WithConntrackFlowsByPort(byPort bool) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		c.byPort = byPort
+		return nil
+	}
+}
+
+// WithConntrackFlowsByDirection splits the aggregates into the traffic sent by the source
+// (original) and by the destination (reply).
+func WithConntrackFlowsByDirection(byDirection bool) ConntrackFlowsOption {
+	return func(c *ConntrackFlows) error {
+		c.byDirection = byDirection
+		return nil
+	}
+}
+
+// NewConntrackFlows creates a new ConntrackFlows collector.
+func NewConntrackFlows(procMountPoint string, opts ...ConntrackFlowsOption) (*ConntrackFlows, error) {
+	c := &ConntrackFlows{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		procMountPoint: procMountPoint,
+		interval:       60 * time.Second,
+		topN:           10,
+		limit:          100000,
+		prefixIPv4:     32,
+		prefixIPv6:     128,
+		previous:       make(map[conntrackFlowID]conntrackFlowCounters),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// conntrackFlowID identifies a conntrack entry across samples.
+type conntrackFlowID struct {
+	protocol        string
+	zone            uint16
+	source          netip.Addr
+	destination     netip.Addr
+	sourcePort      uint16
+	destinationPort uint16
+}
+
+// conntrackFlowCounters holds the counters of both directions of a conntrack entry.
+type conntrackFlowCounters struct {
+	originalBytes, originalPackets uint64
+	replyBytes, replyPackets       uint64
+}
+
+// conntrackFlowKey is the aggregate a flow is counted in. Fields that are not aggregated
+// by are empty.
+type conntrackFlowKey struct {
+	source      string
+	destination string
+	protocol    string
+	port        string
+	direction   string
+}
+
+// conntrackFlowTotal is the traffic of an aggregate over an interval.
+type conntrackFlowTotal struct {
+	key     conntrackFlowKey
+	bytes   int64
+	packets int64
+}
+
+// Start registers the ConntrackFlows gauges and starts sampling the conntrack table until
+// ctx is done.
+func (c *ConntrackFlows) Start(ctx context.Context) error {
+	bytesMetric, err := c.meter.Int64ObservableGauge(
+		"conntrack.flow.bytes",
+		metric.WithDescription("Bytes transferred by the top conntrack flow aggregates during the last sampling interval"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	packetsMetric, err := c.meter.Int64ObservableGauge(
+		"conntrack.flow.packets",
+		metric.WithDescription("Packets transferred by the top conntrack flow aggregates during the last sampling interval"),
+		metric.WithUnit("{packets}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for _, t := range c.top {
+			attrs := []attribute.KeyValue{
+				attribute.String("source.address", t.key.source),
+				attribute.String("destination.address", t.key.destination),
+			}
+			if c.byPort {
+				attrs = append(attrs,
+					attribute.String("protocol", t.key.protocol),
+					attribute.String("destination.port", t.key.port),
+				)
+			}
+			if c.byDirection {
+				attrs = append(attrs, attribute.String("direction", t.key.direction))
+			}
+			opt := metric.WithAttributes(attrs...)
+			o.ObserveInt64(bytesMetric, t.bytes, opt)
+			o.ObserveInt64(packetsMetric, t.packets, opt)
+		}
+		return nil
+	}, bytesMetric, packetsMetric)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.sample(); err != nil {
+				otel.Handle(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// sample reads the conntrack table and replaces the reported aggregates with the traffic
+// since the previous sample.
+func (c *ConntrackFlows) sample() error {
+	var entries []conntrackEntry
+	truncated, err := readConntrack(c.procMountPoint, c.limit, func(e conntrackEntry) {
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return err
+	}
+
+	top := c.aggregate(entries, truncated, time.Now())
+
+	c.mu.Lock()
+	c.top = top
+	c.mu.Unlock()
+	return nil
+}
+
+// aggregate computes the traffic of every flow since the previous sample, taken at now,
+// and returns the top N aggregates by bytes followed by the sum of the others. The first
+// sample only records the counters, as the traffic of the flows began before it.
+func (c *ConntrackFlows) aggregate(entries []conntrackEntry, truncated bool, now time.Time) []conntrackFlowTotal {
+	totals := make(map[conntrackFlowKey]*conntrackFlowTotal)
+	add := func(key conntrackFlowKey, bytes, packets uint64) {
+		if bytes == 0 && packets == 0 {
+			return
+		}
+		t, ok := totals[key]
+		if !ok {
+			t = &conntrackFlowTotal{key: key}
+			totals[key] = t
+		}
+		t.bytes += int64(bytes)
+		t.packets += int64(packets)
+	}
+
+	current := make(map[conntrackFlowID]conntrackFlowCounters, len(entries))
+	for _, e := range entries {
+		id := conntrackFlowID{
+			protocol:        e.Protocol,
+			zone:            e.Zone,
+			source:          e.Original.Source,
+			destination:     e.Original.Destination,
+			sourcePort:      e.Original.SourcePort,
+			destinationPort: e.Original.DestinationPort,
+		}
+		counters := conntrackFlowCounters{
+			originalBytes:   e.Original.Bytes,
+			originalPackets: e.Original.Packets,
+			replyBytes:      e.Reply.Bytes,
+			replyPackets:    e.Reply.Packets,
+		}
+		current[id] = counters
+		if !c.seeded {
+			continue
+		}
+
+		// A flow that is new since the previous sample (or whose counters went backwards,
+		// i.e. it was replaced) is counted from zero. When the previous sample was
+		// truncated, a flow missing from it may just not have been read, and counting its
+		// whole lifetime would make it a spike: it is skipped unless its start timestamp
+		// (net.netfilter.nf_conntrack_timestamp) shows it is new.
+		prev, seen := c.previous[id]
+		if !seen && c.truncated && !e.Start.After(c.sampledAt) {
+			continue
+		}
+		delta := counters
+		if seen &&
+			prev.originalBytes <= counters.originalBytes && prev.originalPackets <= counters.originalPackets &&
+			prev.replyBytes <= counters.replyBytes && prev.replyPackets <= counters.replyPackets {
+			delta = conntrackFlowCounters{
+				originalBytes:   counters.originalBytes - prev.originalBytes,
+				originalPackets: counters.originalPackets - prev.originalPackets,
+				replyBytes:      counters.replyBytes - prev.replyBytes,
+				replyPackets:    counters.replyPackets - prev.replyPackets,
+			}
+		}
+
+		key := conntrackFlowKey{
+			source:      c.aggregateAddr(e.Original.Source),
+			destination: c.aggregateAddr(e.Original.Destination),
+		}
+		if c.byPort {
+			key.protocol = e.Protocol
+			key.port = strconv.Itoa(int(e.Original.DestinationPort))
+		}
+		if c.byDirection {
+			key.direction = "original"
+			add(key, delta.originalBytes, delta.originalPackets)
+			key.direction = "reply"
+			add(key, delta.replyBytes, delta.replyPackets)
+		} else {
+			add(key, delta.originalBytes+delta.replyBytes, delta.originalPackets+delta.replyPackets)
+		}
+	}
+
+	c.previous = current
+	c.sampledAt, c.truncated = now, truncated
+	if !c.seeded {
+		c.seeded = true
+		return nil
+	}
+
+	sorted := make([]conntrackFlowTotal, 0, len(totals))
+	for _, t := range totals {
+		sorted = append(sorted, *t)
+	}
+	slices.SortFunc(sorted, func(a, b conntrackFlowTotal) int {
+		return cmp.Or(
+			cmp.Compare(b.bytes, a.bytes),
+			cmp.Compare(a.key.source, b.key.source),
+			cmp.Compare(a.key.destination, b.key.destination),
+			cmp.Compare(a.key.protocol, b.key.protocol),
+			cmp.Compare(a.key.port, b.key.port),
+			cmp.Compare(a.key.direction, b.key.direction),
+		)
+	})
+	if len(sorted) <= c.topN {
+		return sorted
+	}
+
+	// The others are summed, per direction if split by it.
+	others := make(map[string]*conntrackFlowTotal)
+	for _, t := range sorted[c.topN:] {
+		other, ok := others[t.key.direction]
+		if !ok {
+			other = &conntrackFlowTotal{key: conntrackFlowKey{
+				source:      conntrackFlowsOther,
+				destination: conntrackFlowsOther,
+				direction:   t.key.direction,
+			}}
+			if c.byPort {
+				other.key.protocol, other.key.port = conntrackFlowsOther, conntrackFlowsOther
+			}
+			others[t.key.direction] = other
+		}
+		other.bytes += t.bytes
+		other.packets += t.packets
+	}
+
+	top := sorted[:c.topN:c.topN]
+	for _, direction := range []string{"", "original", "reply"} {
+		if other, ok := others[direction]; ok {
+			top = append(top, *other)
+		}
+	}
+	return top
+}
+
+// aggregateAddr returns an address, or its subnet if aggregating by prefix.
+func (c *ConntrackFlows) aggregateAddr(addr netip.Addr) string {
+	if !addr.IsValid() {
+		return "unknown"
+	}
+
+	bits := c.prefixIPv6
+	if addr.Is4() {
+		bits = c.prefixIPv4
+	}
+	if bits >= addr.BitLen() {
+		return addr.String()
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return addr.String()
+	}
+	return prefix.String()
+}
@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// conntrackFlow returns a TCP conntrack entry with the given counters.
+func conntrackFlow(src, dst string, port uint16, originalBytes, replyBytes uint64) conntrackEntry {
+	return conntrackEntry{
+		Protocol: "tcp",
+		Original: conntrackTuple{
+			Source:          netip.MustParseAddr(src),
+			Destination:     netip.MustParseAddr(dst),
+			SourcePort:      40000,
+			DestinationPort: port,
+			Packets:         originalBytes / 100,
+			Bytes:           originalBytes,
+		},
+		Reply: conntrackTuple{
+			Source:          netip.MustParseAddr(dst),
+			Destination:     netip.MustParseAddr(src),
+			SourcePort:      port,
+			DestinationPort: 40000,
+			Packets:         replyBytes / 100,
+			Bytes:           replyBytes,
+		},
+	}
+}
+
+func TestConntrackFlows(t *testing.T) {
+	c, err := NewConntrackFlows("testdata/proc",
+		WithConntrackFlowsTopN(2),
+		WithConntrackFlowsPrefix(24, 64),
+	)
+	if err != nil {
+		t.Fatalf("failed to create conntrackflows collector: %v", err)
+	}
+
+	// The first sample only records the counters.
+	now := time.Unix(1700000000, 0)
+	if top := c.aggregate([]conntrackEntry{
+		conntrackFlow("10.0.1.5", "192.0.2.1", 443, 1000, 5000),
+		conntrackFlow("10.0.2.7", "192.0.2.1", 443, 100, 100),
+	}, false, now); top != nil {
+		t.Fatalf("expected no aggregates from the first sample, got %v", top)
+	}
+
+	top := c.aggregate([]conntrackEntry{
+		conntrackFlow("10.0.1.5", "192.0.2.1", 443, 2000, 9000),     // +5000 bytes
+		conntrackFlow("10.0.1.6", "192.0.2.1", 443, 500, 500),       // new, +1000 bytes in the same /24
+		conntrackFlow("10.0.2.7", "192.0.2.1", 443, 300, 400),       // +500 bytes
+		conntrackFlow("10.0.3.9", "198.51.100.1", 53, 200, 200),     // new, +400 bytes
+		conntrackFlow("2001:db8::1", "2001:db8:1::1", 80, 100, 100), // new, +200 bytes
+	}, false, now.Add(time.Minute))
+
+	want := []conntrackFlowTotal{
+		{key: conntrackFlowKey{source: "10.0.1.0/24", destination: "192.0.2.0/24"}, bytes: 6000, packets: 60},
+		{key: conntrackFlowKey{source: "10.0.2.0/24", destination: "192.0.2.0/24"}, bytes: 500, packets: 5},
+		{key: conntrackFlowKey{source: "other", destination: "other"}, bytes: 600, packets: 6},
+	}
+	if len(top) != len(want) {
+		t.Fatalf("expected %d aggregates, got %d: %v", len(want), len(top), top)
+	}
+	for i := range want {
+		if top[i] != want[i] {
+			t.Errorf("aggregate %d: expected %+v, got %+v", i, want[i], top[i])
+		}
+	}
+
+	// A flow whose counters went backwards was replaced, and is counted from zero.
+	top = c.aggregate([]conntrackEntry{
+		conntrackFlow("10.0.1.5", "192.0.2.1", 443, 100, 100),
+	}, true, now.Add(2*time.Minute))
+	if len(top) != 1 || top[0].bytes != 200 {
+		t.Errorf("expected a single aggregate of 200 bytes, got %v", top)
+	}
+
+	// The previous sample was truncated, so flows missing from it are only counted when
+	// they started since.
+	unseen := conntrackFlow("10.0.4.1", "192.0.2.1", 443, 50000, 50000)
+	unseen.Start = now.Add(-time.Hour)
+	started := conntrackFlow("10.0.5.1", "192.0.2.1", 443, 300, 300)
+	started.Start = now.Add(150 * time.Second)
+	top = c.aggregate([]conntrackEntry{
+		conntrackFlow("10.0.1.5", "192.0.2.1", 443, 200, 200),   // +200 bytes
+		conntrackFlow("10.0.6.1", "192.0.2.1", 443, 9000, 9000), // no timestamp, skipped
+		unseen,
+		started,
+	}, false, now.Add(3*time.Minute))
+	want = []conntrackFlowTotal{
+		{key: conntrackFlowKey{source: "10.0.5.0/24", destination: "192.0.2.0/24"}, bytes: 600, packets: 6},
+		{key: conntrackFlowKey{source: "10.0.1.0/24", destination: "192.0.2.0/24"}, bytes: 200, packets: 2},
+	}
+	if len(top) != len(want) {
+		t.Fatalf("expected %d aggregates, got %d: %v", len(want), len(top), top)
+	}
+	for i := range want {
+		if top[i] != want[i] {
+			t.Errorf("aggregate %d: expected %+v, got %+v", i, want[i], top[i])
+		}
+	}
+}
+
+func TestConntrackFlowsByPortAndDirection(t *testing.T) {
+	c, err := NewConntrackFlows("testdata/proc",
+		WithConntrackFlowsByPort(true),
+		WithConntrackFlowsByDirection(true),
+	)
+	if err != nil {
+		t.Fatalf("failed to create conntrackflows collector: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	c.aggregate(nil, false, now)
+	top := c.aggregate([]conntrackEntry{
+		conntrackFlow("10.0.1.5", "192.0.2.1", 443, 300, 7000),
+		conntrackFlow("10.0.1.5", "192.0.2.1", 22, 100, 0),
+	}, false, now.Add(time.Minute))
+
+	want := []conntrackFlowTotal{
+		{key: conntrackFlowKey{source: "10.0.1.5", destination: "192.0.2.1", protocol: "tcp", port: "443", direction: "reply"}, bytes: 7000, packets: 70},
+		{key: conntrackFlowKey{source: "10.0.1.5", destination: "192.0.2.1", protocol: "tcp", port: "443", direction: "original"}, bytes: 300, packets: 3},
+		{key: conntrackFlowKey{source: "10.0.1.5", destination: "192.0.2.1", protocol: "tcp", port: "22", direction: "original"}, bytes: 100, packets: 1},
+	}
+	if len(top) != len(want) {
+		t.Fatalf("expected %d aggregates, got %d: %v", len(want), len(top), top)
+	}
+	for i := range want {
+		if top[i] != want[i] {
+			t.Errorf("aggregate %d: expected %+v, got %+v", i, want[i], top[i])
+		}
+	}
+}
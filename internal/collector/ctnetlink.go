@@ -0,0 +1,330 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// ipsAssured is the IPS_ASSURED bit of the conntrack status (linux/netfilter/nf_conntrack_common.h).
+const ipsAssured = 1 << 2
+
+// conntrackProtocols maps IP protocol numbers to the names used by /proc/net/nf_conntrack.
+var conntrackProtocols = map[uint8]string{
+	unix.IPPROTO_ICMP:    "icmp",
+	unix.IPPROTO_TCP:     "tcp",
+	unix.IPPROTO_UDP:     "udp",
+	unix.IPPROTO_DCCP:    "dccp",
+	unix.IPPROTO_GRE:     "gre",
+	unix.IPPROTO_ICMPV6:  "icmpv6",
+	unix.IPPROTO_SCTP:    "sctp",
+	unix.IPPROTO_UDPLITE: "udplite",
+}
+
+// conntrackTCPStates maps the TCP_CONNTRACK_* states to the (lower cased) names used by
+// /proc/net/nf_conntrack.
+var conntrackTCPStates = map[uint8]string{
+	0: "none",
+	1: "syn_sent",
+	2: "syn_recv",
+	3: "established",
+	4: "fin_wait",
+	5: "close_wait",
+	6: "last_ack",
+	7: "time_wait",
+	8: "close",
+	9: "syn_sent2",
+}
+
+// conntrackEntry is an entry of the conntrack table.
+type conntrackEntry struct {
+	Protocol string // e.g. "tcp"
+	State    string // TCP only, e.g. "established"
+	Zone     uint16
+	Assured  bool
+	// ID identifies the entry while it is in the table (ctnetlink only).
+	ID uint32
+	// Start and Stop are when the connection was created and destroyed, set over ctnetlink
+	// when nf_conntrack_timestamp is enabled. Stop is only set in destroy events.
+	Start time.Time
+	Stop  time.Time
+
+	// Original holds the tuple and counters of the direction that created the connection,
+	// and Reply those of the other direction. The counters are zero unless nf_conntrack_acct
+	// is enabled.
+	Original conntrackTuple
+	Reply    conntrackTuple
+}
+
+// conntrackTuple is a direction of a conntrack entry.
+type conntrackTuple struct {
+	Source      netip.Addr
+	Destination netip.Addr
+	// The ports are zero for protocols without ports, such as ICMP.
+	SourcePort      uint16
+	DestinationPort uint16
+	Packets         uint64
+	Bytes           uint64
+}
+
+// parseConntrackEntry parses the attributes of an IPCTNL_MSG_CT_NEW message.
+func parseConntrackEntry(a nlAttrs) (conntrackEntry, error) {
+	var e conntrackEntry
+
+	tuple, ok := a.nested(nl.CTA_TUPLE_ORIG)
+	if !ok {
+		return e, errors.New("conntrack entry has no original tuple")
+	}
+	proto, ok := tuple.nested(nl.CTA_TUPLE_PROTO)
+	if !ok {
+		return e, errors.New("conntrack entry has no protocol")
+	}
+	num, ok := proto.uint8(nl.CTA_PROTO_NUM)
+	if !ok {
+		return e, errors.New("conntrack entry has no protocol number")
+	}
+	e.Protocol = conntrackProtocolName(num)
+
+	e.Original = parseConntrackTuple(tuple, a, nl.CTA_COUNTERS_ORIG)
+	if reply, ok := a.nested(nl.CTA_TUPLE_REPLY); ok {
+		e.Reply = parseConntrackTuple(reply, a, nl.CTA_COUNTERS_REPLY)
+	}
+
+	if info, ok := a.nested(nl.CTA_PROTOINFO); ok {
+		if tcp, ok := info.nested(nl.CTA_PROTOINFO_TCP); ok {
+			if state, ok := tcp.uint8(nl.CTA_PROTOINFO_TCP_STATE); ok {
+				e.State = conntrackTCPStateName(state)
+			}
+		}
+	}
+
+	e.Zone, _ = a.be16(nl.CTA_ZONE)
+	if status, ok := a.be32(nl.CTA_STATUS); ok {
+		e.Assured = status&ipsAssured != 0
+	}
+	e.ID, _ = a.be32(nl.CTA_ID)
+	if ts, ok := a.nested(nl.CTA_TIMESTAMP); ok {
+		if v, ok := ts.be64(nl.CTA_TIMESTAMP_START); ok && v > 0 {
+			e.Start = time.Unix(0, int64(v))
+		}
+		if v, ok := ts.be64(nl.CTA_TIMESTAMP_STOP); ok && v > 0 {
+			e.Stop = time.Unix(0, int64(v))
+		}
+	}
+
+	return e, nil
+}
+
+// parseConntrackTuple parses a CTA_TUPLE_* attribute and the counters of its direction.
+func parseConntrackTuple(tuple, entry nlAttrs, counters uint16) conntrackTuple {
+	var t conntrackTuple
+
+	if ip, ok := tuple.nested(nl.CTA_TUPLE_IP); ok {
+		for _, attr := range []struct {
+			addr *netip.Addr
+			v4   uint16
+			v6   uint16
+		}{
+			{&t.Source, nl.CTA_IP_V4_SRC, nl.CTA_IP_V6_SRC},
+			{&t.Destination, nl.CTA_IP_V4_DST, nl.CTA_IP_V6_DST},
+		} {
+			if v, ok := ip.ip(attr.v4); ok {
+				*attr.addr, _ = netip.AddrFromSlice(v)
+			} else if v, ok := ip.ip(attr.v6); ok {
+				*attr.addr, _ = netip.AddrFromSlice(v)
+			}
+		}
+	}
+	if proto, ok := tuple.nested(nl.CTA_TUPLE_PROTO); ok {
+		t.SourcePort, _ = proto.be16(nl.CTA_PROTO_SRC_PORT)
+		t.DestinationPort, _ = proto.be16(nl.CTA_PROTO_DST_PORT)
+	}
+
+	if c, ok := entry.nested(counters); ok {
+		t.Packets, _ = c.be64(nl.CTA_COUNTERS_PACKETS)
+		t.Bytes, _ = c.be64(nl.CTA_COUNTERS_BYTES)
+	}
+	return t
+}
+
+// conntrackProtocolName returns the name of an IP protocol number.
+func conntrackProtocolName(num uint8) string {
+	if name, ok := conntrackProtocols[num]; ok {
+		return name
+	}
+	return strconv.Itoa(int(num))
+}
+
+// conntrackTCPStateName returns the name of a TCP_CONNTRACK_* state.
+func conntrackTCPStateName(state uint8) string {
+	if name, ok := conntrackTCPStates[state]; ok {
+		return name
+	}
+	return strconv.Itoa(int(state))
+}
+
+// dumpConntrack dumps the conntrack table of every address family over ctnetlink, calling
+// fn with each entry. It stops after limit entries, reporting whether the table was
+// truncated; the socket is closed rather than drained so the kernel stops walking the table.
+func dumpConntrack(limit int, fn func(conntrackEntry)) (bool, error) {
+	s, err := nl.Subscribe(unix.NETLINK_NETFILTER)
+	if err != nil {
+		return false, err
+	}
+	defer s.Close()
+
+	if err := s.SetReceiveTimeout(&nl.SocketTimeoutTv); err != nil {
+		return false, err
+	}
+
+	req := nl.NewNetlinkRequest(unix.NFNL_SUBSYS_CTNETLINK<<8|nl.IPCTNL_MSG_CT_GET, unix.NLM_F_DUMP)
+	req.AddData(&nl.Nfgenmsg{NfgenFamily: unix.AF_UNSPEC, Version: nl.NFNETLINK_V0})
+	if err := s.Send(req); err != nil {
+		return false, err
+	}
+
+	var count int
+	for {
+		msgs, _, err := s.Receive()
+		if err != nil {
+			return false, err
+		}
+
+		for _, m := range msgs {
+			switch m.Header.Type {
+			case unix.NLMSG_DONE:
+				return false, nil
+			case unix.NLMSG_ERROR:
+				if len(m.Data) < 4 {
+					return false, errors.New("short netlink error message")
+				}
+				if errno := int32(nl.NativeEndian().Uint32(m.Data[0:4])); errno != 0 {
+					return false, unix.Errno(-errno)
+				}
+				return false, nil
+			}
+
+			if count >= limit {
+				return true, nil
+			}
+			if len(m.Data) < nl.SizeofNfgenmsg {
+				continue
+			}
+			a, err := parseNLAttrs(m.Data[nl.SizeofNfgenmsg:])
+			if err != nil {
+				return false, err
+			}
+			e, err := parseConntrackEntry(a)
+			if err != nil {
+				continue
+			}
+			fn(e)
+			count++
+		}
+	}
+}
+
+// readProcConntrack reads the conntrack table from /proc/net/nf_conntrack, calling fn with
+// each entry. It stops after limit entries, reporting whether the table was truncated.
+func readProcConntrack(path string, limit int, fn func(conntrackEntry)) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	var count int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		e, ok := parseProcConntrackLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if count >= limit {
+			return true, nil
+		}
+		fn(e)
+		count++
+	}
+	return false, scanner.Err()
+}
+
+// parseProcConntrackLine parses a line of /proc/net/nf_conntrack, such as:
+//
+//	ipv4 2 tcp 6 431999 ESTABLISHED src=10.0.0.1 dst=10.0.0.2 sport=43210 dport=22 packets=3 bytes=180 src=10.0.0.2 ... [ASSURED] mark=0 zone=1 use=2
+//
+// The original tuple is followed by the reply tuple, each with its counters when
+// nf_conntrack_acct is enabled. The zone is only printed when it is not the default zone (0).
+func parseProcConntrackLine(line string) (conntrackEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return conntrackEntry{}, false
+	}
+
+	e := conntrackEntry{Protocol: fields[2]}
+	// tuple is the direction being read; a second "src=" starts the reply.
+	tuple := &e.Original
+	var sources int
+	for i, f := range fields[5:] {
+		key, value, _ := strings.Cut(f, "=")
+		switch {
+		case i == 0 && e.Protocol == "tcp" && !strings.Contains(f, "="):
+			e.State = strings.ToLower(f)
+		case f == "[ASSURED]":
+			e.Assured = true
+		case key == "zone":
+			if v, err := strconv.ParseUint(value, 10, 16); err == nil {
+				e.Zone = uint16(v)
+			}
+		case key == "src":
+			if sources++; sources == 2 {
+				tuple = &e.Reply
+			}
+			tuple.Source, _ = netip.ParseAddr(value)
+		case key == "dst":
+			tuple.Destination, _ = netip.ParseAddr(value)
+		case key == "sport":
+			if v, err := strconv.ParseUint(value, 10, 16); err == nil {
+				tuple.SourcePort = uint16(v)
+			}
+		case key == "dport":
+			if v, err := strconv.ParseUint(value, 10, 16); err == nil {
+				tuple.DestinationPort = uint16(v)
+			}
+		case key == "packets":
+			tuple.Packets, _ = strconv.ParseUint(value, 10, 64)
+		case key == "bytes":
+			tuple.Bytes, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	return e, true
+}
+
+// readConntrack reads the conntrack table over ctnetlink, falling back to
+// /proc/net/nf_conntrack when ctnetlink cannot be used (e.g. without CAP_NET_ADMIN). It
+// stops after limit entries, reporting whether the table was truncated.
+func readConntrack(procMountPoint string, limit int, fn func(conntrackEntry)) (bool, error) {
+	var dumped int
+	truncated, netlinkErr := dumpConntrack(limit, func(e conntrackEntry) {
+		dumped++
+		fn(e)
+	})
+	// Only fall back if nothing was passed on, so no entry is counted twice.
+	if netlinkErr == nil || dumped > 0 {
+		return truncated, netlinkErr
+	}
+
+	truncated, procErr := readProcConntrack(procMountPoint+"/net/nf_conntrack", limit, fn)
+	if procErr != nil {
+		return false, fmt.Errorf("failed to read conntrack table: %w, %w", netlinkErr, procErr)
+	}
+	return truncated, nil
+}
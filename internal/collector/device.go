@@ -13,8 +13,9 @@ import (
 
 // Device collector exposes network interface statistics.
 type Device struct {
-	meter metric.Meter
-	fs    procfs.FS
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
 }
 
 // NewDevice creates a new Device collector.
@@ -25,8 +26,9 @@ func NewDevice(procMountPoint string) (*Device, error) {
 	}
 
 	return &Device{
-		meter: otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
-		fs:    fs,
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
 	}, nil
 }
 
@@ -68,6 +70,42 @@ func (c *Device) Start(ctx context.Context) error {
 		return err
 	}
 
+	ipv6PacketsMetric, err := c.meter.Int64ObservableCounter(
+		"device.ipv6.packets",
+		metric.WithDescription("Network interface IPv6 packets"),
+		metric.WithUnit("{packets}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	ipv6DropsMetric, err := c.meter.Int64ObservableCounter(
+		"device.ipv6.drops",
+		metric.WithDescription("Network interface IPv6 packets dropped"),
+		metric.WithUnit("{packets}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmp6ErrorsMetric, err := c.meter.Int64ObservableCounter(
+		"device.icmp6.errors",
+		metric.WithDescription("Network interface ICMPv6 errors"),
+		metric.WithUnit("{messages}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmp6NeighborMetric, err := c.meter.Int64ObservableCounter(
+		"device.icmp6.neighbor",
+		metric.WithDescription("Network interface ICMPv6 neighbor discovery messages"),
+		metric.WithUnit("{messages}"),
+	)
+	if err != nil {
+		return err
+	}
+
 	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
 		stats, err := c.fs.NetDev()
 		if err != nil {
@@ -94,13 +132,65 @@ func (c *Device) Start(ctx context.Context) error {
 			o.ObserveInt64(packetsMetric, int64(iface.TxPackets), txAttrs)
 			o.ObserveInt64(errorsMetric, int64(iface.TxErrors), txAttrs)
 			o.ObserveInt64(droppedMetric, int64(iface.TxDropped), txAttrs)
+
+			// IPv6 (absent if IPv6 is disabled on the interface)
+			snmp6, err := readDevSNMP6(c.procMountPoint, iface.Name)
+			if err != nil {
+				continue
+			}
+			c.observeIPv6(o, iface.Name, snmp6, ipv6PacketsMetric, ipv6DropsMetric, icmp6ErrorsMetric, icmp6NeighborMetric)
 		}
 		return nil
-	}, ioMetric, packetsMetric, errorsMetric, droppedMetric)
+	}, ioMetric, packetsMetric, errorsMetric, droppedMetric, ipv6PacketsMetric, ipv6DropsMetric, icmp6ErrorsMetric, icmp6NeighborMetric)
 
 	return err
 }
 
+// observeIPv6 reports the per-interface counters from /proc/net/dev_snmp6/<iface>.
+func (c *Device) observeIPv6(o metric.Observer, name string, snmp6 map[string]int64, packets, drops, icmpErrors, neighbor metric.Int64ObservableCounter) {
+	iface := attribute.String("interface", name)
+	receive := attribute.String("direction", "receive")
+	transmit := attribute.String("direction", "transmit")
+
+	if v, ok := snmp6["Ip6InReceives"]; ok {
+		o.ObserveInt64(packets, v, metric.WithAttributes(iface, receive))
+	}
+	if v, ok := snmp6["Ip6OutRequests"]; ok {
+		o.ObserveInt64(packets, v, metric.WithAttributes(iface, transmit))
+	}
+
+	for key, attrs := range map[string][]attribute.KeyValue{
+		"Ip6InHdrErrors":  {receive, attribute.String("reason", "header_error")},
+		"Ip6InAddrErrors": {receive, attribute.String("reason", "address_error")},
+		"Ip6InNoRoutes":   {receive, attribute.String("reason", "no_route")},
+		"Ip6InDiscards":   {receive, attribute.String("reason", "discard")},
+		"Ip6OutDiscards":  {transmit, attribute.String("reason", "discard")},
+		"Ip6OutNoRoutes":  {transmit, attribute.String("reason", "no_route")},
+	} {
+		if v, ok := snmp6[key]; ok {
+			o.ObserveInt64(drops, v, metric.WithAttributes(append(attrs, iface)...))
+		}
+	}
+
+	if v, ok := snmp6["Icmp6InErrors"]; ok {
+		o.ObserveInt64(icmpErrors, v, metric.WithAttributes(iface, receive))
+	}
+	if v, ok := snmp6["Icmp6OutErrors"]; ok {
+		o.ObserveInt64(icmpErrors, v, metric.WithAttributes(iface, transmit))
+	}
+
+	for key, attrs := range map[string][]attribute.KeyValue{
+		"Icmp6InNeighborSolicits":        {receive, attribute.String("type", "solicitation")},
+		"Icmp6InNeighborAdvertisements":  {receive, attribute.String("type", "advertisement")},
+		"Icmp6OutNeighborSolicits":       {transmit, attribute.String("type", "solicitation")},
+		"Icmp6OutNeighborAdvertisements": {transmit, attribute.String("type", "advertisement")},
+	} {
+		if v, ok := snmp6[key]; ok {
+			o.ObserveInt64(neighbor, v, metric.WithAttributes(append(attrs, iface)...))
+		}
+	}
+}
+
 func (c *Device) isLoopback(name string) bool {
 	// TODO: Filter loopback if requested? Implementation plan said "maybe filtering lo".
 	// For now keeping it simple as per plan "starting with all".
@@ -84,4 +84,32 @@ func TestDevice(t *testing.T) {
 			}
 		}
 	}
+
+	// Check device.ipv6.drops
+	// Fixture dev_snmp6/eth0: Ip6InDiscards=5, Ip6OutNoRoutes=4
+	m = findMetric("device.ipv6.drops")
+	if m.Name == "" {
+		t.Error("metric device.ipv6.drops not found")
+	} else {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("device.ipv6.drops is not Sum[int64], got %T", m.Data)
+		} else {
+			foundEth0 := false
+			for _, dp := range sum.DataPoints {
+				ifv, _ := dp.Attributes.Value("interface")
+				dir, _ := dp.Attributes.Value("direction")
+				reason, _ := dp.Attributes.Value("reason")
+				if ifv.AsString() == "eth0" && dir.AsString() == "transmit" && reason.AsString() == "no_route" {
+					if dp.Value != 4 {
+						t.Errorf("eth0 transmit no_route ipv6 drops = %d, want 4", dp.Value)
+					}
+					foundEth0 = true
+				}
+			}
+			if !foundEth0 {
+				t.Error("eth0 transmit no_route ipv6 drops data point not found")
+			}
+		}
+	}
 }
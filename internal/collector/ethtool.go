@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/procfs"
+	"github.com/safchain/ethtool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ethtoolClient is the subset of the ethtool ioctl interface used by the collectors,
+// so that they can be tested without a NIC.
+type ethtoolClient interface {
+	Stats(intf string) (map[string]uint64, error)
+	GetRing(intf string) (ethtool.Ring, error)
+	GetChannels(intf string) (ethtool.Channels, error)
+	GetCoalesce(intf string) (ethtool.Coalesce, error)
+	Features(intf string) (map[string]bool, error)
+	Close()
+}
+
+// Ethtool collector exposes driver statistics (ETHTOOL_GSTATS) for every network interface.
+type Ethtool struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	client         ethtoolClient
+	stats          []*regexp.Regexp
+}
+
+// EthtoolOption configures the Ethtool collector.
+type EthtoolOption func(*Ethtool) error
+
+// WithEthtoolStats only reports the statistics whose name matches one of the regular
+// expressions. Without it, every statistic the driver reports is exported.
+func WithEthtoolStats(patterns ...string) EthtoolOption {
+	return func(c *Ethtool) error {
+		for _, p := range patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("invalid stat pattern %q: %w", p, err)
+			}
+			c.stats = append(c.stats, re)
+		}
+		return nil
+	}
+}
+
+// NewEthtool creates a new Ethtool collector.
+func NewEthtool(procMountPoint string, opts ...EthtoolOption) (*Ethtool, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	client, err := ethtool.NewEthtool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ethtool socket: %w", err)
+	}
+
+	c := &Ethtool{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+		client:         client,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Start registers the Ethtool metrics callbacks.
+func (c *Ethtool) Start(ctx context.Context) error {
+	statsMetric, err := c.meter.Int64ObservableCounter(
+		"device.driver.stats",
+		metric.WithDescription("Network interface driver statistics"),
+	)
+	if err != nil {
+		return err
+	}
+
+	reg, err := c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		devices, err := c.fs.NetDev()
+		if err != nil {
+			return fmt.Errorf("failed to read net dev stats: %w", err)
+		}
+
+		for name := range devices {
+			// Interfaces without driver statistics (such as loopback) return an error.
+			stats, err := c.client.Stats(name)
+			if err != nil {
+				continue
+			}
+
+			for stat, value := range stats {
+				if !c.matches(stat) {
+					continue
+				}
+				o.ObserveInt64(statsMetric, int64(value), metric.WithAttributes(
+					attribute.String("interface", name),
+					attribute.String("stat", stat),
+				))
+			}
+		}
+		return nil
+	}, statsMetric)
+	if err != nil {
+		return err
+	}
+
+	go closeEthtool(ctx, reg, c.client)
+	return nil
+}
+
+// closeEthtool closes the ethtool socket once ctx is done, after unregistering the
+// callback that uses it.
+func closeEthtool(ctx context.Context, reg metric.Registration, client ethtoolClient) {
+	<-ctx.Done()
+	if err := reg.Unregister(); err != nil {
+		otel.Handle(err)
+	}
+	client.Close()
+}
+
+// matches reports whether a statistic is selected by the configured patterns.
+func (c *Ethtool) matches(stat string) bool {
+	if len(c.stats) == 0 {
+		return true
+	}
+	for _, re := range c.stats {
+		if re.MatchString(stat) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/safchain/ethtool"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeEthtool serves ethtool requests from memory, keyed by interface name.
+type fakeEthtool struct {
+	stats    map[string]map[string]uint64
+	rings    map[string]ethtool.Ring
+	channels map[string]ethtool.Channels
+	coalesce map[string]ethtool.Coalesce
+	features map[string]map[string]bool
+}
+
+// errEthtoolNotSupported is returned for interfaces the fake has no data for.
+var errEthtoolNotSupported = errors.New("operation not supported")
+
+func (f *fakeEthtool) Stats(intf string) (map[string]uint64, error) {
+	s, ok := f.stats[intf]
+	if !ok {
+		return nil, errEthtoolNotSupported
+	}
+	return s, nil
+}
+
+func (f *fakeEthtool) GetRing(intf string) (ethtool.Ring, error) {
+	r, ok := f.rings[intf]
+	if !ok {
+		return ethtool.Ring{}, errEthtoolNotSupported
+	}
+	return r, nil
+}
+
+func (f *fakeEthtool) GetChannels(intf string) (ethtool.Channels, error) {
+	ch, ok := f.channels[intf]
+	if !ok {
+		return ethtool.Channels{}, errEthtoolNotSupported
+	}
+	return ch, nil
+}
+
+func (f *fakeEthtool) GetCoalesce(intf string) (ethtool.Coalesce, error) {
+	co, ok := f.coalesce[intf]
+	if !ok {
+		return ethtool.Coalesce{}, errEthtoolNotSupported
+	}
+	return co, nil
+}
+
+func (f *fakeEthtool) Features(intf string) (map[string]bool, error) {
+	feat, ok := f.features[intf]
+	if !ok {
+		return nil, errEthtoolNotSupported
+	}
+	return feat, nil
+}
+
+func (f *fakeEthtool) Close() {}
+
+func TestEthtool(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+
+	c, err := NewEthtool(procPath, WithEthtoolStats("^rx_missed_errors$", "^rx_queue_[0-9]+_packets$"))
+	if err != nil {
+		t.Fatalf("failed to create ethtool collector: %v", err)
+	}
+
+	// Fixture net/dev has lo and eth0; lo does not support driver statistics.
+	c.client = &fakeEthtool{stats: map[string]map[string]uint64{
+		"eth0": {
+			"rx_missed_errors":   7,
+			"rx_queue_0_packets": 100,
+			"rx_queue_1_packets": 200,
+			"tx_timeout_count":   1,
+		},
+	}}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Check device.driver.stats (Sum)
+	m := findMetric("device.driver.stats")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("device.driver.stats is not Sum[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{
+				"rx_missed_errors":   7,
+				"rx_queue_0_packets": 100,
+				"rx_queue_1_packets": 200,
+			}
+			for _, dp := range sum.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				stat, _ := dp.Attributes.Value("stat")
+				if iface.AsString() != "eth0" {
+					t.Errorf("unexpected interface %s", iface.AsString())
+				}
+				expected, ok := want[stat.AsString()]
+				if !ok {
+					t.Errorf("unexpected stat %s", stat.AsString())
+					continue
+				}
+				if dp.Value != expected {
+					t.Errorf("expected %s = %d, got %d", stat.AsString(), expected, dp.Value)
+				}
+				delete(want, stat.AsString())
+			}
+			if len(want) != 0 {
+				t.Errorf("device.driver.stats data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("device.driver.stats not found")
+	}
+}
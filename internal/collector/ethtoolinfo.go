@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/procfs"
+	"github.com/safchain/ethtool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ethtoolFeatures are the offload features reported, by their kernel names (as used by
+// `ethtool -k`): GRO, hardware GRO, LRO, GSO, TSO and receive checksumming.
+var ethtoolFeatures = []string{
+	"rx-gro",
+	"rx-gro-hw",
+	"rx-lro",
+	"tx-generic-segmentation",
+	"tx-tcp-segmentation",
+	"tx-tcp6-segmentation",
+	"rx-checksum",
+}
+
+// EthtoolInfo collector exposes the hardware configuration of network interfaces: ring
+// sizes, channel counts, interrupt coalescing and offload features.
+type EthtoolInfo struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	client         ethtoolClient
+}
+
+// NewEthtoolInfo creates a new EthtoolInfo collector.
+func NewEthtoolInfo(procMountPoint string) (*EthtoolInfo, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	client, err := ethtool.NewEthtool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ethtool socket: %w", err)
+	}
+
+	return &EthtoolInfo{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+		client:         client,
+	}, nil
+}
+
+// ethtoolInfoMetrics holds the instruments of the EthtoolInfo collector.
+type ethtoolInfoMetrics struct {
+	ringCurrent      metric.Int64ObservableGauge
+	ringLimit        metric.Int64ObservableGauge
+	channelsCurrent  metric.Int64ObservableGauge
+	channelsLimit    metric.Int64ObservableGauge
+	coalesceUsecs    metric.Int64ObservableGauge
+	coalesceFrames   metric.Int64ObservableGauge
+	coalesceAdaptive metric.Int64ObservableGauge
+	feature          metric.Int64ObservableGauge
+}
+
+// Start registers the EthtoolInfo metrics callbacks.
+func (c *EthtoolInfo) Start(ctx context.Context) error {
+	var m ethtoolInfoMetrics
+	var err error
+
+	m.ringCurrent, err = c.meter.Int64ObservableGauge(
+		"device.ring.current",
+		metric.WithDescription("Network interface ring size"),
+		metric.WithUnit("{descriptors}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.ringLimit, err = c.meter.Int64ObservableGauge(
+		"device.ring.limit",
+		metric.WithDescription("Network interface maximum ring size"),
+		metric.WithUnit("{descriptors}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.channelsCurrent, err = c.meter.Int64ObservableGauge(
+		"device.channels.current",
+		metric.WithDescription("Network interface channel count"),
+		metric.WithUnit("{channels}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.channelsLimit, err = c.meter.Int64ObservableGauge(
+		"device.channels.limit",
+		metric.WithDescription("Network interface maximum channel count"),
+		metric.WithUnit("{channels}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.coalesceUsecs, err = c.meter.Int64ObservableGauge(
+		"device.coalesce.usecs",
+		metric.WithDescription("Network interface interrupt coalescing delay"),
+		metric.WithUnit("us"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.coalesceFrames, err = c.meter.Int64ObservableGauge(
+		"device.coalesce.frames",
+		metric.WithDescription("Network interface interrupt coalescing frame count"),
+		metric.WithUnit("{frames}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.coalesceAdaptive, err = c.meter.Int64ObservableGauge(
+		"device.coalesce.adaptive",
+		metric.WithDescription("Network interface adaptive interrupt coalescing (1 if enabled, 0 otherwise)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.feature, err = c.meter.Int64ObservableGauge(
+		"device.feature.enabled",
+		metric.WithDescription("Network interface offload feature (1 if enabled, 0 otherwise)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	reg, err := c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		devices, err := c.fs.NetDev()
+		if err != nil {
+			return fmt.Errorf("failed to read net dev stats: %w", err)
+		}
+
+		// Each group of settings is skipped when the driver does not support it.
+		for name := range devices {
+			iface := attribute.String("interface", name)
+
+			if ring, err := c.client.GetRing(name); err == nil {
+				c.observeRing(o, m, iface, ring)
+			}
+			if channels, err := c.client.GetChannels(name); err == nil {
+				c.observeChannels(o, m, iface, channels)
+			}
+			if coalesce, err := c.client.GetCoalesce(name); err == nil {
+				c.observeCoalesce(o, m, iface, coalesce)
+			}
+			if features, err := c.client.Features(name); err == nil {
+				for _, feature := range ethtoolFeatures {
+					enabled, ok := features[feature]
+					if !ok {
+						continue
+					}
+					o.ObserveInt64(m.feature, boolToInt64(enabled),
+						metric.WithAttributes(iface, attribute.String("feature", feature)))
+				}
+			}
+		}
+		return nil
+	}, m.ringCurrent, m.ringLimit, m.channelsCurrent, m.channelsLimit, m.coalesceUsecs, m.coalesceFrames, m.coalesceAdaptive, m.feature)
+	if err != nil {
+		return err
+	}
+
+	go closeEthtool(ctx, reg, c.client)
+	return nil
+}
+
+// observeRing reports the receive and transmit ring sizes.
+func (c *EthtoolInfo) observeRing(o metric.Observer, m ethtoolInfoMetrics, iface attribute.KeyValue, ring ethtool.Ring) {
+	receive := metric.WithAttributes(iface, attribute.String("direction", "receive"))
+	transmit := metric.WithAttributes(iface, attribute.String("direction", "transmit"))
+
+	o.ObserveInt64(m.ringCurrent, int64(ring.RxPending), receive)
+	o.ObserveInt64(m.ringLimit, int64(ring.RxMaxPending), receive)
+	o.ObserveInt64(m.ringCurrent, int64(ring.TxPending), transmit)
+	o.ObserveInt64(m.ringLimit, int64(ring.TxMaxPending), transmit)
+}
+
+// observeChannels reports the channel counts of the types the driver supports.
+func (c *EthtoolInfo) observeChannels(o metric.Observer, m ethtoolInfoMetrics, iface attribute.KeyValue, channels ethtool.Channels) {
+	for _, ch := range []struct {
+		channelType    string
+		current, limit uint32
+	}{
+		{"rx", channels.RxCount, channels.MaxRx},
+		{"tx", channels.TxCount, channels.MaxTx},
+		{"other", channels.OtherCount, channels.MaxOther},
+		{"combined", channels.CombinedCount, channels.MaxCombined},
+	} {
+		// A type the driver does not support has a maximum of zero.
+		if ch.limit == 0 {
+			continue
+		}
+		attrs := metric.WithAttributes(iface, attribute.String("type", ch.channelType))
+		o.ObserveInt64(m.channelsCurrent, int64(ch.current), attrs)
+		o.ObserveInt64(m.channelsLimit, int64(ch.limit), attrs)
+	}
+}
+
+// observeCoalesce reports the receive and transmit interrupt coalescing settings.
+func (c *EthtoolInfo) observeCoalesce(o metric.Observer, m ethtoolInfoMetrics, iface attribute.KeyValue, coalesce ethtool.Coalesce) {
+	receive := metric.WithAttributes(iface, attribute.String("direction", "receive"))
+	transmit := metric.WithAttributes(iface, attribute.String("direction", "transmit"))
+
+	o.ObserveInt64(m.coalesceUsecs, int64(coalesce.RxCoalesceUsecs), receive)
+	o.ObserveInt64(m.coalesceFrames, int64(coalesce.RxMaxCoalescedFrames), receive)
+	o.ObserveInt64(m.coalesceAdaptive, int64(min(coalesce.UseAdaptiveRxCoalesce, 1)), receive)
+	o.ObserveInt64(m.coalesceUsecs, int64(coalesce.TxCoalesceUsecs), transmit)
+	o.ObserveInt64(m.coalesceFrames, int64(coalesce.TxMaxCoalescedFrames), transmit)
+	o.ObserveInt64(m.coalesceAdaptive, int64(min(coalesce.UseAdaptiveTxCoalesce, 1)), transmit)
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/safchain/ethtool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestEthtoolInfo(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+
+	c, err := NewEthtoolInfo(procPath)
+	if err != nil {
+		t.Fatalf("failed to create ethtoolinfo collector: %v", err)
+	}
+
+	// Fixture net/dev has lo and eth0; lo only reports features.
+	c.client = &fakeEthtool{
+		rings: map[string]ethtool.Ring{
+			"eth0": {RxPending: 512, RxMaxPending: 4096, TxPending: 512, TxMaxPending: 4096},
+		},
+		channels: map[string]ethtool.Channels{
+			"eth0": {CombinedCount: 4, MaxCombined: 8},
+		},
+		coalesce: map[string]ethtool.Coalesce{
+			"eth0": {RxCoalesceUsecs: 50, UseAdaptiveRxCoalesce: 1, TxCoalesceUsecs: 100},
+		},
+		features: map[string]map[string]bool{
+			"eth0": {"rx-gro": false, "tx-tcp-segmentation": true, "rx-vlan-filter": true},
+			"lo":   {"rx-gro": true},
+		},
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// values returns the data points of a gauge keyed by the value of the given attributes.
+	values := func(name string, keys ...string) map[string]int64 {
+		m := findMetric(name)
+		if m.Name == "" {
+			t.Errorf("%s not found", name)
+			return nil
+		}
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("%s is not Gauge[int64], got %T", name, m.Data)
+			return nil
+		}
+		result := make(map[string]int64)
+		for _, dp := range gauge.DataPoints {
+			var key string
+			for i, k := range keys {
+				v, _ := dp.Attributes.Value(attribute.Key(k))
+				if i > 0 {
+					key += "/"
+				}
+				key += v.AsString()
+			}
+			result[key] = dp.Value
+		}
+		return result
+	}
+
+	check := func(name string, got, want map[string]int64) {
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %d data points, got %d (%v)", name, len(want), len(got), got)
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s %s: expected %d, got %d", name, k, v, got[k])
+			}
+		}
+	}
+
+	check("device.ring.limit", values("device.ring.limit", "interface", "direction"), map[string]int64{
+		"eth0/receive":  4096,
+		"eth0/transmit": 4096,
+	})
+	check("device.channels.current", values("device.channels.current", "interface", "type"), map[string]int64{
+		"eth0/combined": 4,
+	})
+	check("device.coalesce.adaptive", values("device.coalesce.adaptive", "interface", "direction"), map[string]int64{
+		"eth0/receive":  1,
+		"eth0/transmit": 0,
+	})
+	check("device.feature.enabled", values("device.feature.enabled", "interface", "feature"), map[string]int64{
+		"eth0/rx-gro":              0,
+		"eth0/tx-tcp-segmentation": 1,
+		"lo/rx-gro":                1,
+	})
+}
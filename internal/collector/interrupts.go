@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Interrupts collector exposes the per-CPU interrupt counts of network interface IRQs.
+type Interrupts struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	sysMountPoint  string
+}
+
+// InterruptsOption configures the Interrupts collector.
+type InterruptsOption func(*Interrupts) error
+
+// WithInterruptsSysMountPoint sets where sysfs is mounted (defaults to /sys), to read the
+// MSI IRQs of the devices of interfaces whose IRQs are not named after them.
+func WithInterruptsSysMountPoint(sysMountPoint string) InterruptsOption {
+	return func(c *Interrupts) error {
+		c.sysMountPoint = sysMountPoint
+		return nil
+	}
+}
+
+// NewInterrupts creates a new Interrupts collector.
+func NewInterrupts(procMountPoint string, opts ...InterruptsOption) (*Interrupts, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	c := &Interrupts{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+		sysMountPoint:  "/sys",
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// nicInterrupt is an IRQ line of a network interface.
+type nicInterrupt struct {
+	iface string
+	queue string // queue number, if the IRQ serves a single queue
+	kind  string // e.g. "TxRx", "rx" or "tx"
+	// counts maps the CPU number to the interrupts it handled.
+	counts map[string]int64
+}
+
+// Start registers the Interrupts metrics callbacks.
+func (c *Interrupts) Start(ctx context.Context) error {
+	interruptsMetric, err := c.meter.Int64ObservableCounter(
+		"device.interrupts",
+		metric.WithDescription("Network interface interrupts handled per CPU"),
+		metric.WithUnit("{interrupts}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		devices, err := c.fs.NetDev()
+		if err != nil {
+			return fmt.Errorf("failed to read net dev stats: %w", err)
+		}
+		names := make([]string, 0, len(devices))
+		for name := range devices {
+			names = append(names, name)
+		}
+
+		irqs, err := c.read(names)
+		if err != nil {
+			return fmt.Errorf("failed to read interrupts: %w", err)
+		}
+
+		for _, irq := range irqs {
+			for cpu, count := range irq.counts {
+				attrs := []attribute.KeyValue{
+					attribute.String("interface", irq.iface),
+					attribute.String("cpu", cpu),
+				}
+				if irq.queue != "" {
+					attrs = append(attrs, attribute.String("queue", irq.queue))
+				}
+				if irq.kind != "" {
+					attrs = append(attrs, attribute.String("queue.type", irq.kind))
+				}
+				o.ObserveInt64(interruptsMetric, count, metric.WithAttributes(attrs...))
+			}
+		}
+		return nil
+	}, interruptsMetric)
+
+	return err
+}
+
+// read parses /proc/interrupts and returns the IRQs whose action name belongs to one of
+// the interfaces, or that are MSI IRQs of the device of one of them.
+func (c *Interrupts) read(ifaces []string) ([]nicInterrupt, error) {
+	deviceIRQs := c.deviceIRQs(ifaces)
+
+	file, err := os.Open(c.procMountPoint + "/interrupts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Lines are long on hosts with many CPUs.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	// The header names the online CPUs, e.g. "CPU0 CPU1 CPU3".
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("interrupts empty")
+	}
+	var cpus []string
+	for _, f := range strings.Fields(scanner.Text()) {
+		cpus = append(cpus, strings.TrimPrefix(f, "CPU"))
+	}
+
+	var irqs []nicInterrupt
+	for scanner.Scan() {
+		// IRQ: count... chip [hwirq] action[, action...]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < len(cpus)+2 {
+			continue
+		}
+		// Only numbered IRQs carry device actions (not NMI, LOC and so on).
+		number := strings.TrimSuffix(fields[0], ":")
+		if _, err := strconv.Atoi(number); err != nil {
+			continue
+		}
+
+		var iface, queue, kind string
+		var found bool
+		actions := fields[len(cpus)+1:]
+		for _, action := range actions {
+			if iface, queue, kind, found = matchNICAction(strings.TrimSuffix(action, ","), ifaces); found {
+				break
+			}
+		}
+		// Some drivers name the IRQs after the driver or the PCI device instead, e.g.
+		// "mlx5_comp0@pci:0000:08:00.0" or "iwlwifi:queue_1".
+		if !found {
+			if iface, found = deviceIRQs[number]; found {
+				action, _, _ := strings.Cut(strings.TrimSuffix(actions[len(actions)-1], ","), "@")
+				queue, kind = splitIRQQueue(action)
+			}
+		}
+		if !found {
+			continue
+		}
+
+		irq := nicInterrupt{iface: iface, queue: queue, kind: kind, counts: make(map[string]int64, len(cpus))}
+		for i, cpu := range cpus {
+			v, err := strconv.ParseInt(fields[i+1], 10, 64)
+			if err != nil {
+				continue
+			}
+			irq.counts[cpu] = v
+		}
+		irqs = append(irqs, irq)
+	}
+
+	return irqs, scanner.Err()
+}
+
+// matchNICAction maps an IRQ action name to an interface and queue. Drivers name the
+// actions of queue IRQs after the interface, e.g. "eth0-TxRx-3", "eth0-rx-0" or
+// "ens5-Tx-Rx-0", some with the driver name in front, e.g. "i40e-eth0-TxRx-3": the queue
+// is the trailing number, as in /sys/class/net/<iface>/queues, and the kind is the part
+// before it ("TxRx", "rx", "Tx-Rx"). Other IRQs of the interface, e.g. "eth0-lsc", only
+// have a kind, and a single IRQ named "eth0" has neither. The longest matching interface
+// name wins, so "eth0.100-rx-0" is not taken for eth0.
+func matchNICAction(action string, ifaces []string) (iface, queue, kind string, ok bool) {
+	for _, name := range ifaces {
+		if len(name) <= len(iface) {
+			continue
+		}
+		// The interface name is a "-" delimited part of the action.
+		for i := 0; i+len(name) <= len(action); i++ {
+			end := i + len(name)
+			if action[i:end] != name || (i > 0 && action[i-1] != '-') || (end < len(action) && action[end] != '-') {
+				continue
+			}
+			iface, ok = name, true
+			queue, kind = splitIRQQueue(strings.TrimPrefix(action[end:], "-"))
+			break
+		}
+	}
+	return iface, queue, kind, ok
+}
+
+// splitIRQQueue splits the part of an action name that follows the interface into the
+// queue, its trailing number, and the kind before it, without the separator.
+func splitIRQQueue(s string) (queue, kind string) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	return s[i:], strings.TrimRight(s[:i], "-_")
+}
+
+// deviceIRQs maps the MSI IRQs of the devices of the interfaces, from
+// /sys/class/net/<iface>/device/msi_irqs, to the interface. IRQs of devices shared by
+// several interfaces are left out, as they cannot be told apart.
+func (c *Interrupts) deviceIRQs(ifaces []string) map[string]string {
+	irqs := make(map[string]string)
+	shared := make(map[string]bool)
+	for _, name := range ifaces {
+		entries, err := os.ReadDir(filepath.Join(c.sysMountPoint, "class", "net", name, "device", "msi_irqs"))
+		if err != nil {
+			// Virtual interfaces have no device.
+			continue
+		}
+		for _, e := range entries {
+			if _, ok := irqs[e.Name()]; ok {
+				shared[e.Name()] = true
+			}
+			irqs[e.Name()] = name
+		}
+	}
+	for irq := range shared {
+		delete(irqs, irq)
+	}
+	return irqs
+}
@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInterrupts(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	sysPath, _ := filepath.Abs("testdata/sys")
+
+	c, err := NewInterrupts(procPath, WithInterruptsSysMountPoint(sysPath))
+	if err != nil {
+		t.Fatalf("failed to create interrupts collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture interrupts: eth0 (no queue), eth0-TxRx-0, eth0-TxRx-1 and i40e-eth0-TxRx-2 on
+	// two CPUs, and mlx5_comp3, which is not named after eth0 but is an MSI IRQ of its device.
+
+	// Check device.interrupts (Sum)
+	m := findMetric("device.interrupts")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("device.interrupts is not Sum[int64], got %T", m.Data)
+		} else {
+			want := map[[3]string]int64{
+				{"", "", "0"}:           1500,
+				{"", "", "1"}:           0,
+				{"0", "TxRx", "0"}:      90210,
+				{"0", "TxRx", "1"}:      12,
+				{"1", "TxRx", "0"}:      88000,
+				{"1", "TxRx", "1"}:      3,
+				{"2", "TxRx", "0"}:      700,
+				{"2", "TxRx", "1"}:      7,
+				{"3", "mlx5_comp", "0"}: 40,
+				{"3", "mlx5_comp", "1"}: 4,
+			}
+			for _, dp := range sum.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				queue, _ := dp.Attributes.Value("queue")
+				kind, _ := dp.Attributes.Value("queue.type")
+				cpu, _ := dp.Attributes.Value("cpu")
+				key := [3]string{queue.AsString(), kind.AsString(), cpu.AsString()}
+				if iface.AsString() != "eth0" {
+					t.Errorf("unexpected interface %s", iface.AsString())
+				}
+				expected, ok := want[key]
+				if !ok {
+					t.Errorf("unexpected data point %v", key)
+					continue
+				}
+				if dp.Value != expected {
+					t.Errorf("expected %v = %d, got %d", key, expected, dp.Value)
+				}
+				delete(want, key)
+			}
+			if len(want) != 0 {
+				t.Errorf("device.interrupts data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("device.interrupts not found")
+	}
+}
+
+func TestMatchNICAction(t *testing.T) {
+	ifaces := []string{"eth0", "eth0.100", "ens5"}
+	for _, tc := range []struct {
+		action      string
+		iface       string
+		queue, kind string
+		ok          bool
+	}{
+		{"eth0", "eth0", "", "", true},
+		{"eth0-TxRx-3", "eth0", "3", "TxRx", true},
+		{"eth0-rx-0", "eth0", "0", "rx", true},
+		{"ens5-Tx-Rx-1", "ens5", "1", "Tx-Rx", true},
+		{"eth0-2", "eth0", "2", "", true},
+		{"eth0-lsc", "eth0", "", "lsc", true},
+		{"eth0.100-tx-0", "eth0.100", "0", "tx", true},
+		{"i40e-eth0-TxRx-2", "eth0", "2", "TxRx", true},
+		{"ice-ens5-TxRx-0", "ens5", "0", "TxRx", true},
+		{"i40e-0000:00:03.0:misc", "", "", "", false},
+		{"veth0-rx-0", "", "", "", false},
+		{"mlx5_comp0", "", "", "", false},
+	} {
+		iface, queue, kind, ok := matchNICAction(tc.action, ifaces)
+		if iface != tc.iface || queue != tc.queue || kind != tc.kind || ok != tc.ok {
+			t.Errorf("%s: got %q %q %q %v, want %q %q %q %v", tc.action, iface, queue, kind, ok, tc.iface, tc.queue, tc.kind, tc.ok)
+		}
+	}
+}
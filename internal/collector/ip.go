@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// icmpTypes maps ICMPv4 message type numbers to names.
+var icmpTypes = map[int]string{
+	0:  "echo_reply",
+	3:  "destination_unreachable",
+	4:  "source_quench",
+	5:  "redirect",
+	8:  "echo_request",
+	9:  "router_advertisement",
+	10: "router_solicitation",
+	11: "time_exceeded",
+	12: "parameter_problem",
+	13: "timestamp",
+	14: "timestamp_reply",
+}
+
+// IP collector exposes IP and ICMP protocol statistics.
+type IP struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+}
+
+// NewIP creates a new IP collector.
+func NewIP(procMountPoint string) (*IP, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &IP{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+	}, nil
+}
+
+// Start registers the IP and ICMP metrics callbacks.
+func (c *IP) Start(ctx context.Context) error {
+	forwarded, err := c.meter.Int64ObservableCounter(
+		"ip.forwarded",
+		metric.WithDescription("IP datagrams forwarded"),
+		metric.WithUnit("{datagram}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	drops, err := c.meter.Int64ObservableCounter(
+		"ip.drops",
+		metric.WithDescription("IP datagrams dropped"),
+		metric.WithUnit("{datagram}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	fragmentFailures, err := c.meter.Int64ObservableCounter(
+		"ip.fragmentation.failures",
+		metric.WithDescription("IP fragmentation and reassembly failures"),
+		metric.WithUnit("{failure}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmpMessages, err := c.meter.Int64ObservableCounter(
+		"icmp.messages",
+		metric.WithDescription("ICMP messages by type"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmpErrors, err := c.meter.Int64ObservableCounter(
+		"icmp.errors",
+		metric.WithDescription("ICMP messages that could not be received or sent"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	version := attribute.String("ip.version", "4")
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		snmp, err := readNetSNMP(c.procMountPoint)
+		if err != nil {
+			return fmt.Errorf("failed to read net snmp: %w", err)
+		}
+
+		// SNMP Ip Keys: ForwDatagrams, InHdrErrors, InAddrErrors, InDiscards, OutDiscards, OutNoRoutes, ReasmFails, FragFails
+
+		if v, ok := snmp.IP["ForwDatagrams"]; ok {
+			o.ObserveInt64(forwarded, v, metric.WithAttributes(version))
+		}
+
+		for key, attrs := range map[string][]attribute.KeyValue{
+			"InHdrErrors":  {attribute.String("direction", "in"), attribute.String("reason", "header_error")},
+			"InAddrErrors": {attribute.String("direction", "in"), attribute.String("reason", "address_error")},
+			"InDiscards":   {attribute.String("direction", "in"), attribute.String("reason", "discard")},
+			"OutDiscards":  {attribute.String("direction", "out"), attribute.String("reason", "discard")},
+			"OutNoRoutes":  {attribute.String("direction", "out"), attribute.String("reason", "no_route")},
+		} {
+			if v, ok := snmp.IP[key]; ok {
+				o.ObserveInt64(drops, v, metric.WithAttributes(append(attrs, version)...))
+			}
+		}
+
+		if v, ok := snmp.IP["ReasmFails"]; ok {
+			o.ObserveInt64(fragmentFailures, v, metric.WithAttributes(attribute.String("operation", "reassembly"), version))
+		}
+		if v, ok := snmp.IP["FragFails"]; ok {
+			o.ObserveInt64(fragmentFailures, v, metric.WithAttributes(attribute.String("operation", "fragmentation"), version))
+		}
+
+		// SNMP IcmpMsg Keys: InTypeN, OutTypeN where N is the ICMP message type.
+
+		for key, v := range snmp.ICMPMsg {
+			direction, msgType, ok := parseICMPMsgKey(key, icmpTypes)
+			if !ok {
+				continue
+			}
+			o.ObserveInt64(icmpMessages, v, metric.WithAttributes(
+				attribute.String("direction", direction),
+				attribute.String("type", msgType),
+				version,
+			))
+		}
+
+		if v, ok := snmp.ICMP["InErrors"]; ok {
+			o.ObserveInt64(icmpErrors, v, metric.WithAttributes(attribute.String("direction", "in"), version))
+		}
+		if v, ok := snmp.ICMP["OutErrors"]; ok {
+			o.ObserveInt64(icmpErrors, v, metric.WithAttributes(attribute.String("direction", "out"), version))
+		}
+
+		return nil
+	}, forwarded, drops, fragmentFailures, icmpMessages, icmpErrors)
+
+	return err
+}
+
+// parseICMPMsgKey splits an IcmpMsg key such as "InType3" into its direction and
+// message type name. Types without a known name are reported by number.
+func parseICMPMsgKey(key string, names map[int]string) (direction, msgType string, ok bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(key, "InType"):
+		direction, rest = "in", strings.TrimPrefix(key, "InType")
+	case strings.HasPrefix(key, "OutType"):
+		direction, rest = "out", strings.TrimPrefix(key, "OutType")
+	default:
+		return "", "", false
+	}
+
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return "", "", false
+	}
+	if name, ok := names[n]; ok {
+		return direction, name, true
+	}
+	return direction, rest, true
+}
@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestIP(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewIP(procPath)
+	if err != nil {
+		t.Fatalf("failed to create ip collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture Ip: ForwDatagrams=300, InDiscards=7, OutNoRoutes=9, ReasmFails=4, FragFails=5
+	// Fixture IcmpMsg: InType3=12, InType8=4, OutType0=4, OutType3=9
+
+	// Check ip.forwarded (Sum)
+	m := findMetric("ip.forwarded")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("ip.forwarded is not Sum[int64], got %T", m.Data)
+		} else {
+			if len(sum.DataPoints) > 0 && sum.DataPoints[0].Value != 300 {
+				t.Errorf("ip.forwarded = %d, want 300", sum.DataPoints[0].Value)
+			}
+		}
+	} else {
+		t.Error("ip.forwarded not found")
+	}
+
+	// Check ip.drops (Sum)
+	m = findMetric("ip.drops")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("ip.drops is not Sum[int64], got %T", m.Data)
+		} else {
+			foundNoRoute := false
+			for _, dp := range sum.DataPoints {
+				reason, _ := dp.Attributes.Value("reason")
+				if reason.AsString() == "no_route" {
+					if dp.Value != 9 {
+						t.Errorf("ip no_route drops = %d, want 9", dp.Value)
+					}
+					foundNoRoute = true
+				}
+			}
+			if !foundNoRoute {
+				t.Error("ip no_route drops data point not found")
+			}
+		}
+	} else {
+		t.Error("ip.drops not found")
+	}
+
+	// Check icmp.messages (Sum)
+	m = findMetric("icmp.messages")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("icmp.messages is not Sum[int64], got %T", m.Data)
+		} else {
+			foundUnreachable := false
+			for _, dp := range sum.DataPoints {
+				dir, _ := dp.Attributes.Value("direction")
+				msgType, _ := dp.Attributes.Value("type")
+				if dir.AsString() == "out" && msgType.AsString() == "destination_unreachable" {
+					if dp.Value != 9 {
+						t.Errorf("icmp out destination_unreachable = %d, want 9", dp.Value)
+					}
+					foundUnreachable = true
+				}
+			}
+			if !foundUnreachable {
+				t.Error("icmp out destination_unreachable data point not found")
+			}
+		}
+	} else {
+		t.Error("icmp.messages not found")
+	}
+}
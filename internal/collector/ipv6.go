@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// icmp6Types maps ICMPv6 message type numbers to names.
+var icmp6Types = map[int]string{
+	1:   "destination_unreachable",
+	2:   "packet_too_big",
+	3:   "time_exceeded",
+	4:   "parameter_problem",
+	128: "echo_request",
+	129: "echo_reply",
+	130: "multicast_listener_query",
+	131: "multicast_listener_report",
+	132: "multicast_listener_done",
+	133: "router_solicitation",
+	134: "router_advertisement",
+	135: "neighbor_solicitation",
+	136: "neighbor_advertisement",
+	137: "redirect",
+	143: "multicast_listener_report_v2",
+}
+
+// IPv6 collector exposes IPv6, ICMPv6 and UDP over IPv6 protocol statistics.
+//
+// Metrics share their names with the IPv4 collectors (IP, UDP) and are
+// distinguished by the "ip.version" attribute.
+type IPv6 struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+}
+
+// NewIPv6 creates a new IPv6 collector.
+func NewIPv6(procMountPoint string) (*IPv6, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &IPv6{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+	}, nil
+}
+
+// Start registers the IPv6 metrics callbacks.
+func (c *IPv6) Start(ctx context.Context) error {
+	forwarded, err := c.meter.Int64ObservableCounter(
+		"ip.forwarded",
+		metric.WithDescription("IP datagrams forwarded"),
+		metric.WithUnit("{datagram}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	drops, err := c.meter.Int64ObservableCounter(
+		"ip.drops",
+		metric.WithDescription("IP datagrams dropped"),
+		metric.WithUnit("{datagram}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	fragmentFailures, err := c.meter.Int64ObservableCounter(
+		"ip.fragmentation.failures",
+		metric.WithDescription("IP fragmentation and reassembly failures"),
+		metric.WithUnit("{failure}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmpMessages, err := c.meter.Int64ObservableCounter(
+		"icmp.messages",
+		metric.WithDescription("ICMP messages by type"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	icmpErrors, err := c.meter.Int64ObservableCounter(
+		"icmp.errors",
+		metric.WithDescription("ICMP messages that could not be received or sent"),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	udpPackets, err := c.meter.Int64ObservableCounter(
+		"udp.packets",
+		metric.WithDescription("UDP packets statistics"),
+	)
+	if err != nil {
+		return err
+	}
+
+	udpDrops, err := c.meter.Int64ObservableCounter(
+		"udp.drops",
+		metric.WithDescription("UDP drops statistics"),
+	)
+	if err != nil {
+		return err
+	}
+
+	udpLitePackets, err := c.meter.Int64ObservableCounter(
+		"udplite.packets",
+		metric.WithDescription("UDP-Lite packets statistics"),
+	)
+	if err != nil {
+		return err
+	}
+
+	udpLiteDrops, err := c.meter.Int64ObservableCounter(
+		"udplite.drops",
+		metric.WithDescription("UDP-Lite drops statistics"),
+	)
+	if err != nil {
+		return err
+	}
+
+	version := attribute.String("ip.version", "6")
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		snmp6, err := readNetSNMP6(c.procMountPoint)
+		if err != nil {
+			return fmt.Errorf("failed to read net snmp6: %w", err)
+		}
+
+		// SNMP6 Ip6 Keys: Ip6OutForwDatagrams, Ip6InHdrErrors, Ip6InAddrErrors, Ip6InDiscards,
+		// Ip6OutDiscards, Ip6OutNoRoutes, Ip6ReasmFails, Ip6FragFails
+
+		if v, ok := snmp6["Ip6OutForwDatagrams"]; ok {
+			o.ObserveInt64(forwarded, v, metric.WithAttributes(version))
+		}
+
+		for key, attrs := range map[string][]attribute.KeyValue{
+			"Ip6InHdrErrors":  {attribute.String("direction", "in"), attribute.String("reason", "header_error")},
+			"Ip6InAddrErrors": {attribute.String("direction", "in"), attribute.String("reason", "address_error")},
+			"Ip6InDiscards":   {attribute.String("direction", "in"), attribute.String("reason", "discard")},
+			"Ip6OutDiscards":  {attribute.String("direction", "out"), attribute.String("reason", "discard")},
+			"Ip6OutNoRoutes":  {attribute.String("direction", "out"), attribute.String("reason", "no_route")},
+		} {
+			if v, ok := snmp6[key]; ok {
+				o.ObserveInt64(drops, v, metric.WithAttributes(append(attrs, version)...))
+			}
+		}
+
+		if v, ok := snmp6["Ip6ReasmFails"]; ok {
+			o.ObserveInt64(fragmentFailures, v, metric.WithAttributes(attribute.String("operation", "reassembly"), version))
+		}
+		if v, ok := snmp6["Ip6FragFails"]; ok {
+			o.ObserveInt64(fragmentFailures, v, metric.WithAttributes(attribute.String("operation", "fragmentation"), version))
+		}
+
+		// SNMP6 Icmp6 Keys: Icmp6InTypeN, Icmp6OutTypeN, Icmp6InErrors, Icmp6OutErrors
+
+		for key, v := range snmp6 {
+			if !strings.HasPrefix(key, "Icmp6") {
+				continue
+			}
+			direction, msgType, ok := parseICMPMsgKey(strings.TrimPrefix(key, "Icmp6"), icmp6Types)
+			if !ok {
+				continue
+			}
+			o.ObserveInt64(icmpMessages, v, metric.WithAttributes(
+				attribute.String("direction", direction),
+				attribute.String("type", msgType),
+				version,
+			))
+		}
+
+		if v, ok := snmp6["Icmp6InErrors"]; ok {
+			o.ObserveInt64(icmpErrors, v, metric.WithAttributes(attribute.String("direction", "in"), version))
+		}
+		if v, ok := snmp6["Icmp6OutErrors"]; ok {
+			o.ObserveInt64(icmpErrors, v, metric.WithAttributes(attribute.String("direction", "out"), version))
+		}
+
+		// SNMP6 Udp6/UdpLite6 Keys: InDatagrams, OutDatagrams, InErrors, NoPorts, RcvbufErrors
+
+		observeUDP6(o, snmp6, "Udp6", udpPackets, udpDrops, version)
+		observeUDP6(o, snmp6, "UdpLite6", udpLitePackets, udpLiteDrops, version)
+
+		return nil
+	}, forwarded, drops, fragmentFailures, icmpMessages, icmpErrors, udpPackets, udpDrops, udpLitePackets, udpLiteDrops)
+
+	return err
+}
+
+// observeUDP6 reports the UDP counters found under prefix using the same
+// attributes as the UDP collector.
+func observeUDP6(o metric.Observer, snmp6 map[string]int64, prefix string, packets, drops metric.Int64ObservableCounter, version attribute.KeyValue) {
+	if v, ok := snmp6[prefix+"InDatagrams"]; ok {
+		o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "in"), attribute.String("type", "datagrams"), version))
+	}
+	if v, ok := snmp6[prefix+"OutDatagrams"]; ok {
+		o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "out"), attribute.String("type", "datagrams"), version))
+	}
+	if v, ok := snmp6[prefix+"InErrors"]; ok {
+		o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("type", "errors"), version))
+	}
+
+	if v, ok := snmp6[prefix+"NoPorts"]; ok {
+		o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "no_port"), version))
+	}
+	if v, ok := snmp6[prefix+"RcvbufErrors"]; ok {
+		o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "rcv_buf"), version))
+	}
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestIPv6(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewIPv6(procPath)
+	if err != nil {
+		t.Fatalf("failed to create ipv6 collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture snmp6: Ip6OutForwDatagrams=150, Ip6OutNoRoutes=11, Icmp6InType135=20,
+	// Udp6InDatagrams=250, Udp6NoPorts=7, UdpLite6InDatagrams=12
+
+	// Check ip.forwarded (Sum)
+	m := findMetric("ip.forwarded")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("ip.forwarded is not Sum[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range sum.DataPoints {
+				version, _ := dp.Attributes.Value("ip.version")
+				if version.AsString() == "6" {
+					if dp.Value != 150 {
+						t.Errorf("ipv6 forwarded = %d, want 150", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("ipv6 forwarded data point not found")
+			}
+		}
+	} else {
+		t.Error("ip.forwarded not found")
+	}
+
+	// Check icmp.messages (Sum)
+	m = findMetric("icmp.messages")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("icmp.messages is not Sum[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range sum.DataPoints {
+				dir, _ := dp.Attributes.Value("direction")
+				msgType, _ := dp.Attributes.Value("type")
+				if dir.AsString() == "in" && msgType.AsString() == "neighbor_solicitation" {
+					if dp.Value != 20 {
+						t.Errorf("icmp6 in neighbor_solicitation = %d, want 20", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("icmp6 in neighbor_solicitation data point not found")
+			}
+		}
+	} else {
+		t.Error("icmp.messages not found")
+	}
+
+	// Check udp.drops (Sum)
+	m = findMetric("udp.drops")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("udp.drops is not Sum[int64], got %T", m.Data)
+		} else {
+			foundNoPort := false
+			for _, dp := range sum.DataPoints {
+				reason, _ := dp.Attributes.Value("reason")
+				version, _ := dp.Attributes.Value("ip.version")
+				if reason.AsString() == "no_port" && version.AsString() == "6" {
+					if dp.Value != 7 {
+						t.Errorf("udp6 no_port drops = %d, want 7", dp.Value)
+					}
+					foundNoPort = true
+				}
+			}
+			if !foundNoPort {
+				t.Error("udp6 no_port drops data point not found")
+			}
+		}
+	} else {
+		t.Error("udp.drops not found")
+	}
+
+	// Check udplite.packets (Sum)
+	if m := findMetric("udplite.packets"); m.Name == "" {
+		t.Error("udplite.packets not found")
+	}
+}
@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// linkState is the last known state of a network interface.
+type linkState struct {
+	name string
+	up   bool
+	mtu  int
+}
+
+// LinkEvents collector emits a log record for every interface up/down, address add/remove
+// and MTU change, as reported by rtnetlink.
+type LinkEvents struct {
+	meter       metric.Meter
+	logger      log.Logger
+	links       map[int]linkState
+	transitions metric.Int64Counter
+}
+
+// NewLinkEvents creates a new LinkEvents collector.
+func NewLinkEvents() (*LinkEvents, error) {
+	return &LinkEvents{
+		meter:  otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		logger: global.Logger("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		links:  make(map[int]linkState),
+	}, nil
+}
+
+// Start subscribes to the RTNLGRP_LINK, RTNLGRP_IPV4_IFADDR and RTNLGRP_IPV6_IFADDR
+// multicast groups, handling the updates until ctx is cancelled.
+func (c *LinkEvents) Start(ctx context.Context) error {
+	var err error
+	c.transitions, err = c.meter.Int64Counter(
+		"device.link.transitions",
+		metric.WithDescription("Network interface operational state transitions"),
+		metric.WithUnit("{transitions}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Both subscriptions end when either fails to start or the handler stops, so neither
+	// is left blocked sending updates nobody reads.
+	ctx, cancel := context.WithCancel(ctx)
+	// Receiving fails once ctx is cancelled and the sockets are closed.
+	handleErr := func(err error) {
+		if ctx.Err() == nil {
+			otel.Handle(err)
+		}
+	}
+
+	linkCh := make(chan netlink.LinkUpdate)
+	if err := netlink.LinkSubscribeWithOptions(linkCh, ctx.Done(), netlink.LinkSubscribeOptions{
+		ErrorCallback: handleErr,
+	}); err != nil {
+		cancel()
+		return fmt.Errorf("failed to subscribe to link updates: %w", err)
+	}
+
+	addrCh := make(chan netlink.AddrUpdate)
+	if err := netlink.AddrSubscribeWithOptions(addrCh, ctx.Done(), netlink.AddrSubscribeOptions{
+		ErrorCallback: handleErr,
+	}); err != nil {
+		cancel()
+		return fmt.Errorf("failed to subscribe to address updates: %w", err)
+	}
+
+	// Seed the known links after subscribing so no change is missed; the first update
+	// for a link is compared against this state rather than reported as a change.
+	links, err := netlink.LinkList()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to list links: %w", err)
+	}
+	for _, l := range links {
+		attrs := l.Attrs()
+		c.links[attrs.Index] = linkState{name: attrs.Name, up: linkIsUp(attrs), mtu: attrs.MTU}
+	}
+
+	go func() {
+		defer cancel()
+
+		// A closed channel means its subscription failed (the error was passed to
+		// otel.Handle); the other one is still served.
+		for linkCh != nil || addrCh != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-linkCh:
+				if !ok {
+					linkCh = nil
+					continue
+				}
+				c.handleLink(ctx, u)
+			case u, ok := <-addrCh:
+				if !ok {
+					addrCh = nil
+					continue
+				}
+				c.handleAddr(ctx, u)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleLink compares a link update with the last known state of the interface and
+// reports up/down and MTU changes.
+func (c *LinkEvents) handleLink(ctx context.Context, u netlink.LinkUpdate) {
+	attrs := u.Attrs()
+
+	if u.Header.Type == unix.RTM_DELLINK {
+		delete(c.links, attrs.Index)
+		return
+	}
+
+	next := linkState{name: attrs.Name, up: linkIsUp(attrs), mtu: attrs.MTU}
+	prev, ok := c.links[attrs.Index]
+	c.links[attrs.Index] = next
+	if !ok {
+		// A new interface; there is no previous state to compare against.
+		return
+	}
+
+	iface := log.String("interface", next.name)
+
+	if prev.up != next.up {
+		state, severity := "down", log.SeverityWarn
+		if next.up {
+			state, severity = "up", log.SeverityInfo
+		}
+
+		c.transitions.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("interface", next.name),
+			attribute.String("direction", state),
+		))
+		c.emit(ctx, "device.link.state", severity,
+			fmt.Sprintf("interface %s is %s", next.name, state),
+			iface,
+			log.String("state", state),
+			log.String("operstate", attrs.OperState.String()),
+		)
+	}
+
+	if prev.mtu != next.mtu {
+		c.emit(ctx, "device.link.mtu", log.SeverityInfo,
+			fmt.Sprintf("interface %s MTU changed from %d to %d", next.name, prev.mtu, next.mtu),
+			iface,
+			log.Int("mtu", next.mtu),
+			log.Int("mtu.previous", prev.mtu),
+		)
+	}
+}
+
+// handleAddr reports an address being added to or removed from an interface.
+func (c *LinkEvents) handleAddr(ctx context.Context, u netlink.AddrUpdate) {
+	name := strconv.Itoa(u.LinkIndex)
+	if l, ok := c.links[u.LinkIndex]; ok {
+		name = l.name
+	}
+
+	action, verb := "removed", "removed from"
+	if u.NewAddr {
+		action, verb = "added", "added to"
+	}
+
+	version := "6"
+	if u.LinkAddress.IP.To4() != nil {
+		version = "4"
+	}
+
+	c.emit(ctx, "device.address", log.SeverityInfo,
+		fmt.Sprintf("address %s %s interface %s", u.LinkAddress.String(), verb, name),
+		log.String("interface", name),
+		log.String("address", u.LinkAddress.String()),
+		log.String("action", action),
+		log.String("ip.version", version),
+	)
+}
+
+// emit writes a log record for an event.
+func (c *LinkEvents) emit(ctx context.Context, event string, severity log.Severity, body string, attrs ...log.KeyValue) {
+	var r log.Record
+	r.SetEventName(event)
+	r.SetTimestamp(time.Now())
+	r.SetSeverity(severity)
+	r.SetBody(log.StringValue(body))
+	r.AddAttributes(attrs...)
+	c.logger.Emit(ctx, r)
+}
+
+// linkIsUp reports whether an interface can pass traffic. Interfaces without carrier
+// detection (such as loopback and tunnels) report an unknown operational state, so for
+// those the administrative and lower layer flags are used.
+func linkIsUp(attrs *netlink.LinkAttrs) bool {
+	switch attrs.OperState {
+	case netlink.OperUp:
+		return true
+	case netlink.OperUnknown:
+		return attrs.Flags&net.FlagUp != 0 && attrs.RawFlags&unix.IFF_LOWER_UP != 0
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/sys/unix"
+)
+
+// recordExporter keeps the exported log records in memory.
+type recordExporter struct {
+	records []sdklog.Record
+}
+
+func (e *recordExporter) Export(_ context.Context, records []sdklog.Record) error {
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordExporter) ForceFlush(context.Context) error { return nil }
+
+func TestLinkEvents(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	exporter := &recordExporter{}
+	global.SetLoggerProvider(sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter))))
+
+	c, err := NewLinkEvents()
+	if err != nil {
+		t.Fatalf("failed to create linkevents collector: %v", err)
+	}
+
+	// Drive the handlers directly rather than through a subscription, which would need
+	// permission to change links.
+	c.transitions, err = c.meter.Int64Counter("device.link.transitions")
+	if err != nil {
+		t.Fatalf("failed to create counter: %v", err)
+	}
+	c.links[2] = linkState{name: "eth0", up: true, mtu: 1500}
+
+	ctx := context.Background()
+	link := func(operState netlink.LinkOperState, mtu int) netlink.LinkUpdate {
+		return netlink.LinkUpdate{
+			Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK},
+			Link: &netlink.Device{LinkAttrs: netlink.LinkAttrs{
+				Index:     2,
+				Name:      "eth0",
+				MTU:       mtu,
+				OperState: operState,
+			}},
+		}
+	}
+
+	c.handleLink(ctx, link(netlink.OperUp, 1500))   // No change
+	c.handleLink(ctx, link(netlink.OperDown, 1500)) // Down
+	c.handleLink(ctx, link(netlink.OperUp, 9000))   // Up, MTU change
+	c.handleAddr(ctx, netlink.AddrUpdate{
+		LinkIndex:   2,
+		LinkAddress: net.IPNet{IP: net.ParseIP("192.0.2.1").To4(), Mask: net.CIDRMask(24, 32)},
+		NewAddr:     true,
+	})
+
+	// A link that was not seen before is recorded without an event.
+	c.handleLink(ctx, netlink.LinkUpdate{
+		Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK},
+		Link:   &netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 3, Name: "eth1", OperState: netlink.OperDown}},
+	})
+
+	want := []struct {
+		event string
+		attrs map[string]string
+	}{
+		{"device.link.state", map[string]string{"interface": "eth0", "state": "down"}},
+		{"device.link.state", map[string]string{"interface": "eth0", "state": "up"}},
+		{"device.link.mtu", map[string]string{"interface": "eth0", "mtu": "9000", "mtu.previous": "1500"}},
+		{"device.address", map[string]string{"interface": "eth0", "address": "192.0.2.1/24", "action": "added", "ip.version": "4"}},
+	}
+
+	if len(exporter.records) != len(want) {
+		t.Fatalf("expected %d log records, got %d", len(want), len(exporter.records))
+	}
+	for i, w := range want {
+		r := exporter.records[i]
+		if r.EventName() != w.event {
+			t.Errorf("record %d: expected event %s, got %s", i, w.event, r.EventName())
+		}
+		for key, value := range w.attrs {
+			found := false
+			r.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == key {
+					found = true
+					if kv.Value.String() != value {
+						t.Errorf("record %d: expected %s=%s, got %s", i, key, value, kv.Value.String())
+					}
+					return false
+				}
+				return true
+			})
+			if !found {
+				t.Errorf("record %d: attribute %s not found", i, key)
+			}
+		}
+	}
+
+	// Check device.link.transitions (Sum)
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	if len(rm.ScopeMetrics) == 0 || len(rm.ScopeMetrics[0].Metrics) == 0 {
+		t.Fatal("device.link.transitions not found")
+	}
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("device.link.transitions is not Sum[int64], got %T", rm.ScopeMetrics[0].Metrics[0].Data)
+	}
+	for _, dp := range sum.DataPoints {
+		if dp.Value != 1 {
+			direction, _ := dp.Attributes.Value("direction")
+			t.Errorf("expected 1 %s transition, got %d", direction.AsString(), dp.Value)
+		}
+	}
+	if len(sum.DataPoints) != 2 {
+		t.Errorf("expected 2 device.link.transitions data points, got %d", len(sum.DataPoints))
+	}
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// tcpListenState is the sock_diag state mask for listening sockets.
+const tcpListenState = 1 << 0x0A
+
+// Listen collector exposes the accept queue of each listening TCP socket, read over
+// netlink sock_diag.
+type Listen struct {
+	meter metric.Meter
+}
+
+// NewListen creates a new Listen collector.
+func NewListen() (*Listen, error) {
+	return &Listen{
+		meter: otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+	}, nil
+}
+
+// listenKey identifies a listening address. Sockets sharing one (SO_REUSEPORT) are summed.
+type listenKey struct {
+	address string
+	port    uint16
+}
+
+// listenQueue holds the accept queue of a listening address.
+type listenQueue struct {
+	current int64
+	limit   int64
+}
+
+// Start registers the Listen metrics callbacks.
+func (c *Listen) Start(ctx context.Context) error {
+	current, err := c.meter.Int64ObservableGauge(
+		"tcp.listen.queue.current",
+		metric.WithDescription("Connections waiting in the accept queue of listening TCP sockets"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	limit, err := c.meter.Int64ObservableGauge(
+		"tcp.listen.queue.limit",
+		metric.WithDescription("Configured backlog of listening TCP sockets"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	utilization, err := c.meter.Float64ObservableGauge(
+		"tcp.listen.queue.utilization",
+		metric.WithDescription("Fraction of the backlog of listening TCP sockets in use"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		queues, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read listening sockets: %w", err)
+		}
+
+		for key, q := range queues {
+			attrs := metric.WithAttributes(
+				attribute.String("local.address", key.address),
+				attribute.String("local.port", strconv.Itoa(int(key.port))),
+			)
+			o.ObserveInt64(current, q.current, attrs)
+			o.ObserveInt64(limit, q.limit, attrs)
+			if q.limit > 0 {
+				o.ObserveFloat64(utilization, float64(q.current)/float64(q.limit), attrs)
+			}
+		}
+
+		return nil
+	}, current, limit, utilization)
+
+	return err
+}
+
+// read dumps the listening IPv4 and IPv6 TCP sockets. For a listening socket the
+// kernel reports the accept queue length as rqueue and the backlog as wqueue.
+func (c *Listen) read() (map[listenKey]*listenQueue, error) {
+	queues := make(map[listenKey]*listenQueue)
+
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		err := dumpInetDiag(family, unix.IPPROTO_TCP, 0, tcpListenState, func(s *inetDiagSocket) {
+			key := listenKey{address: s.LocalAddress.String(), port: s.LocalPort}
+			q, ok := queues[key]
+			if !ok {
+				q = &listenQueue{}
+				queues[key] = q
+			}
+			q.current += int64(s.RQueue)
+			q.limit += int64(s.WQueue)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return queues, nil
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestListen(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	// Listen on an ephemeral port so there is at least one socket to report.
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+	c, err := NewListen()
+	if err != nil {
+		t.Fatalf("failed to create listen collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Check tcp.listen.queue.limit (Gauge)
+	m := findMetric("tcp.listen.queue.limit")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("tcp.listen.queue.limit is not Gauge[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range gauge.DataPoints {
+				addr, _ := dp.Attributes.Value("local.address")
+				p, _ := dp.Attributes.Value("local.port")
+				if addr.AsString() == "127.0.0.1" && p.AsString() == port {
+					if dp.Value <= 0 {
+						t.Errorf("backlog for 127.0.0.1:%s = %d, want > 0", port, dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("listener 127.0.0.1:%s data point not found", port)
+			}
+		}
+	} else {
+		t.Error("tcp.listen.queue.limit not found")
+	}
+}
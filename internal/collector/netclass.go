@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/procfs/sysfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// operStates are the RFC 2863 operational states reported in /sys/class/net/<iface>/operstate.
+var operStates = []string{"unknown", "notpresent", "down", "lowerlayerdown", "testing", "dormant", "up"}
+
+// duplexModes are the modes reported in /sys/class/net/<iface>/duplex.
+var duplexModes = []string{"full", "half", "unknown"}
+
+// NetClass collector exposes network interface attributes from /sys/class/net.
+type NetClass struct {
+	meter         metric.Meter
+	fs            sysfs.FS
+	sysMountPoint string
+}
+
+// NewNetClass creates a new NetClass collector.
+func NewNetClass(sysMountPoint string) (*NetClass, error) {
+	fs, err := sysfs.NewFS(sysMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	return &NetClass{
+		meter:         otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:            fs,
+		sysMountPoint: sysMountPoint,
+	}, nil
+}
+
+// Start registers the NetClass metrics callbacks.
+func (c *NetClass) Start(ctx context.Context) error {
+	speedMetric, err := c.meter.Int64ObservableGauge(
+		"device.link.speed",
+		metric.WithDescription("Network interface negotiated link speed"),
+		metric.WithUnit("bit/s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	mtuMetric, err := c.meter.Int64ObservableGauge(
+		"device.mtu",
+		metric.WithDescription("Network interface MTU"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	txQueueLenMetric, err := c.meter.Int64ObservableGauge(
+		"device.transmit_queue.length",
+		metric.WithDescription("Network interface transmit queue length"),
+		metric.WithUnit("{packets}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	operStateMetric, err := c.meter.Int64ObservableGauge(
+		"device.link.operstate",
+		metric.WithDescription("Network interface operational state (1 for the current state, 0 otherwise)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	duplexMetric, err := c.meter.Int64ObservableGauge(
+		"device.link.duplex",
+		metric.WithDescription("Network interface duplex mode (1 for the current mode, 0 otherwise)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	carrierChangesMetric, err := c.meter.Int64ObservableCounter(
+		"device.carrier.changes",
+		metric.WithDescription("Network interface carrier changes"),
+		metric.WithUnit("{changes}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	carrierTransitionsMetric, err := c.meter.Int64ObservableCounter(
+		"device.carrier.transitions",
+		metric.WithDescription("Network interface carrier transitions by direction"),
+		metric.WithUnit("{transitions}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ifaces, err := c.fs.NetClass()
+		if err != nil {
+			return fmt.Errorf("failed to read net class: %w", err)
+		}
+
+		for _, iface := range ifaces {
+			name := attribute.String("interface", iface.Name)
+			attrs := metric.WithAttributes(name)
+
+			// Speed is -1 or unreadable for virtual interfaces and links that are down.
+			if iface.Speed != nil && *iface.Speed >= 0 {
+				o.ObserveInt64(speedMetric, *iface.Speed*1000*1000, attrs)
+			}
+			if iface.MTU != nil {
+				o.ObserveInt64(mtuMetric, *iface.MTU, attrs)
+			}
+			if iface.TxQueueLen != nil {
+				o.ObserveInt64(txQueueLenMetric, *iface.TxQueueLen, attrs)
+			}
+
+			if iface.OperState != "" {
+				for _, state := range operStates {
+					o.ObserveInt64(operStateMetric, boolToInt64(iface.OperState == state),
+						metric.WithAttributes(name, attribute.String("state", state)))
+				}
+			}
+			if iface.Duplex != "" {
+				for _, mode := range duplexModes {
+					o.ObserveInt64(duplexMetric, boolToInt64(iface.Duplex == mode),
+						metric.WithAttributes(name, attribute.String("duplex", mode)))
+				}
+			}
+
+			if iface.CarrierChanges != nil {
+				o.ObserveInt64(carrierChangesMetric, *iface.CarrierChanges, attrs)
+			}
+			if iface.CarrierUpCount != nil {
+				o.ObserveInt64(carrierTransitionsMetric, *iface.CarrierUpCount,
+					metric.WithAttributes(name, attribute.String("direction", "up")))
+			}
+			if iface.CarrierDownCount != nil {
+				o.ObserveInt64(carrierTransitionsMetric, *iface.CarrierDownCount,
+					metric.WithAttributes(name, attribute.String("direction", "down")))
+			}
+		}
+		return nil
+	}, speedMetric, mtuMetric, txQueueLenMetric, operStateMetric, duplexMetric, carrierChangesMetric, carrierTransitionsMetric)
+
+	return err
+}
+
+// boolToInt64 returns 1 for true and 0 for false.
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNetClass(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	sysPath, _ := filepath.Abs("testdata/sys")
+
+	c, err := NewNetClass(sysPath)
+	if err != nil {
+		t.Fatalf("failed to create netclass collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture: eth0 1000Mb/s full duplex up, lo unknown, wg0 speed -1 down
+
+	// Check device.link.speed (Gauge)
+	m := findMetric("device.link.speed")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("device.link.speed is not Gauge[int64], got %T", m.Data)
+		} else {
+			if len(gauge.DataPoints) != 1 {
+				t.Errorf("expected 1 device.link.speed data point, got %d", len(gauge.DataPoints))
+			}
+			for _, dp := range gauge.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				if iface.AsString() != "eth0" || dp.Value != 1000000000 {
+					t.Errorf("unexpected speed %d for %s", dp.Value, iface.AsString())
+				}
+			}
+		}
+	} else {
+		t.Error("device.link.speed not found")
+	}
+
+	// Check device.mtu (Gauge)
+	m = findMetric("device.mtu")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("device.mtu is not Gauge[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{"eth0": 1500, "lo": 65536, "wg0": 1420}
+			for _, dp := range gauge.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				if dp.Value != want[iface.AsString()] {
+					t.Errorf("expected mtu %d for %s, got %d", want[iface.AsString()], iface.AsString(), dp.Value)
+				}
+			}
+		}
+	} else {
+		t.Error("device.mtu not found")
+	}
+
+	// Check device.link.operstate (Gauge)
+	m = findMetric("device.link.operstate")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("device.link.operstate is not Gauge[int64], got %T", m.Data)
+		} else {
+			want := map[string]string{"eth0": "up", "lo": "unknown", "wg0": "down"}
+			for _, dp := range gauge.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				state, _ := dp.Attributes.Value("state")
+				var expected int64
+				if want[iface.AsString()] == state.AsString() {
+					expected = 1
+				}
+				if dp.Value != expected {
+					t.Errorf("expected operstate %s=%d for %s, got %d", state.AsString(), expected, iface.AsString(), dp.Value)
+				}
+			}
+		}
+	} else {
+		t.Error("device.link.operstate not found")
+	}
+
+	// Check device.carrier.transitions (Sum)
+	m = findMetric("device.carrier.transitions")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("device.carrier.transitions is not Sum[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range sum.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				direction, _ := dp.Attributes.Value("direction")
+				if iface.AsString() == "eth0" && direction.AsString() == "down" {
+					if dp.Value != 2 {
+						t.Errorf("expected 2 eth0 down transitions, got %d", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("eth0 down transitions data point not found")
+			}
+		}
+	} else {
+		t.Error("device.carrier.transitions not found")
+	}
+}
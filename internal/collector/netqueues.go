@@ -0,0 +1,174 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs/sysfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// NetQueues collector exposes per-queue statistics from /sys/class/net/<iface>/queues.
+type NetQueues struct {
+	meter         metric.Meter
+	fs            sysfs.FS
+	sysMountPoint string
+}
+
+// NewNetQueues creates a new NetQueues collector.
+func NewNetQueues(sysMountPoint string) (*NetQueues, error) {
+	fs, err := sysfs.NewFS(sysMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	return &NetQueues{
+		meter:         otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:            fs,
+		sysMountPoint: sysMountPoint,
+	}, nil
+}
+
+// Start registers the NetQueues metrics callbacks.
+func (c *NetQueues) Start(ctx context.Context) error {
+	bqlLimitMetric, err := c.meter.Int64ObservableGauge(
+		"device.queue.bql.limit",
+		metric.WithDescription("Network interface transmit queue byte queue limit"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bqlInflightMetric, err := c.meter.Int64ObservableGauge(
+		"device.queue.bql.inflight",
+		metric.WithDescription("Network interface transmit queue bytes in flight"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	timeoutsMetric, err := c.meter.Int64ObservableCounter(
+		"device.queue.timeouts",
+		metric.WithDescription("Network interface transmit queue timeouts"),
+		metric.WithUnit("{timeouts}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	cpusMetric, err := c.meter.Int64ObservableGauge(
+		"device.queue.cpus",
+		metric.WithDescription("CPUs configured for a network interface queue by RPS (receive) or XPS (transmit)"),
+		metric.WithUnit("{cpus}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		devices, err := c.fs.NetClassDevices()
+		if err != nil {
+			return fmt.Errorf("failed to read net class devices: %w", err)
+		}
+
+		for _, name := range devices {
+			dir := filepath.Join(c.sysMountPoint, "class", "net", name, "queues")
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+
+			for _, entry := range entries {
+				direction, queue, ok := parseQueueName(entry.Name())
+				if !ok {
+					continue
+				}
+				path := filepath.Join(dir, entry.Name())
+				attrs := metric.WithAttributes(
+					attribute.String("interface", name),
+					attribute.String("queue", queue),
+					attribute.String("direction", direction),
+				)
+
+				// Each file is optional: BQL and XPS depend on the kernel configuration and
+				// reading a mask fails on single-queue devices.
+				if direction == "transmit" {
+					if v, err := readFileInt(filepath.Join(path, "byte_queue_limits", "limit")); err == nil {
+						o.ObserveInt64(bqlLimitMetric, v, attrs)
+					}
+					if v, err := readFileInt(filepath.Join(path, "byte_queue_limits", "inflight")); err == nil {
+						o.ObserveInt64(bqlInflightMetric, v, attrs)
+					}
+					if v, err := readFileInt(filepath.Join(path, "tx_timeout")); err == nil {
+						o.ObserveInt64(timeoutsMetric, v, attrs)
+					}
+				}
+
+				maskFile := "rps_cpus"
+				if direction == "transmit" {
+					maskFile = "xps_cpus"
+				}
+				if mask, count, err := readCPUMask(filepath.Join(path, maskFile)); err == nil {
+					o.ObserveInt64(cpusMetric, count, metric.WithAttributes(
+						attribute.String("interface", name),
+						attribute.String("queue", queue),
+						attribute.String("direction", direction),
+						attribute.String("mask", mask),
+					))
+				}
+			}
+		}
+		return nil
+	}, bqlLimitMetric, bqlInflightMetric, timeoutsMetric, cpusMetric)
+
+	return err
+}
+
+// parseQueueName parses a queue directory name such as "rx-0" or "tx-3".
+func parseQueueName(name string) (direction, queue string, ok bool) {
+	prefix, queue, found := strings.Cut(name, "-")
+	if !found {
+		return "", "", false
+	}
+	if _, err := strconv.Atoi(queue); err != nil {
+		return "", "", false
+	}
+
+	switch prefix {
+	case "rx":
+		return "receive", queue, true
+	case "tx":
+		return "transmit", queue, true
+	}
+	return "", "", false
+}
+
+// readCPUMask reads a CPU bitmap such as "00000000,0000000f", returning it and the number
+// of CPUs it selects.
+func readCPUMask(path string) (string, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	mask := strings.TrimSpace(string(data))
+
+	var count int64
+	for _, group := range strings.Split(mask, ",") {
+		v, err := strconv.ParseUint(group, 16, 32)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid cpu mask %q: %w", mask, err)
+		}
+		count += int64(bits.OnesCount32(uint32(v)))
+	}
+
+	return mask, count, nil
+}
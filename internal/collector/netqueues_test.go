@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNetQueues(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	sysPath, _ := filepath.Abs("testdata/sys")
+
+	c, err := NewNetQueues(sysPath)
+	if err != nil {
+		t.Fatalf("failed to create netqueues collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture: eth0 has rx-0, rx-1, tx-0 and tx-1; lo has rx-0 and tx-0 without BQL or XPS.
+
+	// Check device.queue.bql.limit (Gauge)
+	m := findMetric("device.queue.bql.limit")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("device.queue.bql.limit is not Gauge[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{"0": 30000, "1": 45000}
+			if len(gauge.DataPoints) != len(want) {
+				t.Errorf("expected %d device.queue.bql.limit data points, got %d", len(want), len(gauge.DataPoints))
+			}
+			for _, dp := range gauge.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				queue, _ := dp.Attributes.Value("queue")
+				if iface.AsString() != "eth0" || dp.Value != want[queue.AsString()] {
+					t.Errorf("unexpected limit %d for %s queue %s", dp.Value, iface.AsString(), queue.AsString())
+				}
+			}
+		}
+	} else {
+		t.Error("device.queue.bql.limit not found")
+	}
+
+	// Check device.queue.timeouts (Sum)
+	m = findMetric("device.queue.timeouts")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("device.queue.timeouts is not Sum[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range sum.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				queue, _ := dp.Attributes.Value("queue")
+				if iface.AsString() == "eth0" && queue.AsString() == "1" {
+					if dp.Value != 3 {
+						t.Errorf("expected 3 timeouts for eth0 queue 1, got %d", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("eth0 queue 1 timeouts data point not found")
+			}
+		}
+	} else {
+		t.Error("device.queue.timeouts not found")
+	}
+
+	// Check device.queue.cpus (Gauge)
+	m = findMetric("device.queue.cpus")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("device.queue.cpus is not Gauge[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range gauge.DataPoints {
+				iface, _ := dp.Attributes.Value("interface")
+				queue, _ := dp.Attributes.Value("queue")
+				direction, _ := dp.Attributes.Value("direction")
+				if iface.AsString() == "eth0" && queue.AsString() == "0" && direction.AsString() == "receive" {
+					mask, _ := dp.Attributes.Value("mask")
+					if dp.Value != 2 || mask.AsString() != "00000000,00000003" {
+						t.Errorf("expected 2 cpus (00000000,00000003) for eth0 rx-0, got %d (%s)", dp.Value, mask.AsString())
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("eth0 rx-0 cpus data point not found")
+			}
+		}
+	} else {
+		t.Error("device.queue.cpus not found")
+	}
+}
@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// nl80211FamilyName is the name of the nl80211 generic netlink family.
+const nl80211FamilyName = "nl80211"
+
+// nl80211ChannelWidths maps the NL80211_CHAN_WIDTH_* values to their width in MHz.
+var nl80211ChannelWidths = map[uint32]int64{
+	unix.NL80211_CHAN_WIDTH_20_NOHT: 20,
+	unix.NL80211_CHAN_WIDTH_20:      20,
+	unix.NL80211_CHAN_WIDTH_40:      40,
+	unix.NL80211_CHAN_WIDTH_80:      80,
+	unix.NL80211_CHAN_WIDTH_80P80:   160,
+	unix.NL80211_CHAN_WIDTH_160:     160,
+	unix.NL80211_CHAN_WIDTH_5:       5,
+	unix.NL80211_CHAN_WIDTH_10:      10,
+	unix.NL80211_CHAN_WIDTH_1:       1,
+	unix.NL80211_CHAN_WIDTH_2:       2,
+	unix.NL80211_CHAN_WIDTH_4:       4,
+	unix.NL80211_CHAN_WIDTH_8:       8,
+	unix.NL80211_CHAN_WIDTH_16:      16,
+	unix.NL80211_CHAN_WIDTH_320:     320,
+}
+
+// genlMsg is a struct genlmsghdr (linux/genetlink.h) carrying a command.
+type genlMsg struct {
+	cmd uint8
+}
+
+func (m *genlMsg) Len() int { return nl.SizeofGenlmsg }
+
+func (m *genlMsg) Serialize() []byte {
+	// The version and reserved fields are left zeroed.
+	return []byte{m.cmd, 0, 0, 0}
+}
+
+// parseGenlMsg parses the attributes following the generic netlink header of a message.
+func parseGenlMsg(msg []byte) (uint8, nlAttrs, error) {
+	if len(msg) < nl.SizeofGenlmsg {
+		return 0, nil, fmt.Errorf("generic netlink message too short: %d bytes", len(msg))
+	}
+	attrs, err := parseNLAttrs(msg[nl.SizeofGenlmsg:])
+	if err != nil {
+		return 0, nil, err
+	}
+	return msg[0], attrs, nil
+}
+
+// dumpNL80211 requests a dump of an nl80211 command, calling fn with the attributes of
+// each message. attrs are added to the request, e.g. to select an interface.
+func dumpNL80211(family uint16, cmd uint8, attrs []*nl.RtAttr, fn func(nlAttrs)) error {
+	req := nl.NewNetlinkRequest(int(family), unix.NLM_F_DUMP)
+	req.AddData(&genlMsg{cmd: cmd})
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+
+	var parseErr error
+	err := req.ExecuteIter(unix.NETLINK_GENERIC, family, func(msg []byte) bool {
+		_, a, err := parseGenlMsg(msg)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		fn(a)
+		return true
+	})
+	// An interrupted dump is still useful; the table changed while it was read.
+	if err != nil && !errors.Is(err, netlink.ErrDumpInterrupted) {
+		return err
+	}
+	return parseErr
+}
+
+// requestNL80211 sends an nl80211 command and waits for it to be acknowledged.
+func requestNL80211(family uint16, cmd uint8, attrs []*nl.RtAttr) error {
+	req := nl.NewNetlinkRequest(int(family), unix.NLM_F_ACK)
+	req.AddData(&genlMsg{cmd: cmd})
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+
+	_, err := req.Execute(unix.NETLINK_GENERIC, 0)
+	return err
+}
+
+// listWifiInterfaces returns the wireless interfaces of every type.
+func listWifiInterfaces(family uint16) ([]wifiInterface, error) {
+	var ifaces []wifiInterface
+	err := dumpNL80211(family, unix.NL80211_CMD_GET_INTERFACE, nil, func(a nlAttrs) {
+		ifaces = append(ifaces, parseWifiInterface(a))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump wifi interfaces: %w", err)
+	}
+	return ifaces, nil
+}
+
+// listWifiStations returns the stations of an interface; for a managed interface, the
+// access point it is associated with.
+func listWifiStations(family uint16, ifindex uint32) ([]wifiStation, error) {
+	var stations []wifiStation
+	err := dumpNL80211(family, unix.NL80211_CMD_GET_STATION, []*nl.RtAttr{
+		nl.NewRtAttr(unix.NL80211_ATTR_IFINDEX, nl.Uint32Attr(ifindex)),
+	}, func(a nlAttrs) {
+		if sta, err := parseWifiStation(a); err == nil {
+			stations = append(stations, sta)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
+
+// subscribeNL80211 opens a socket receiving the events of an nl80211 multicast group.
+func subscribeNL80211(family *netlink.GenlFamily, group string) (*nl.NetlinkSocket, error) {
+	var id uint32
+	var found bool
+	for _, g := range family.Groups {
+		if g.Name == group {
+			id, found = g.ID, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("nl80211 has no %s multicast group", group)
+	}
+
+	s, err := nl.Subscribe(unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	// Generic netlink group IDs are allocated dynamically and may not fit the 32 bit group
+	// mask used when binding, so the group is joined explicitly.
+	if err := unix.SetsockoptInt(s.GetFd(), unix.SOL_NETLINK, unix.NETLINK_ADD_MEMBERSHIP, int(id)); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// wifiInterface is a wireless interface returned by NL80211_CMD_GET_INTERFACE.
+type wifiInterface struct {
+	Index        uint32
+	Name         string
+	Type         uint32
+	SSID         string
+	Frequency    *int64 // MHz
+	ChannelWidth *int64 // MHz
+}
+
+// parseWifiInterface parses the attributes of an NL80211_CMD_GET_INTERFACE message.
+func parseWifiInterface(a nlAttrs) wifiInterface {
+	var ifi wifiInterface
+	ifi.Index, _ = a.uint32(unix.NL80211_ATTR_IFINDEX)
+	ifi.Name, _ = a.string(unix.NL80211_ATTR_IFNAME)
+	ifi.Type, _ = a.uint32(unix.NL80211_ATTR_IFTYPE)
+	// The SSID is not NUL terminated and may contain any bytes.
+	ifi.SSID = string(a[unix.NL80211_ATTR_SSID])
+
+	if v, ok := a.uint32(unix.NL80211_ATTR_WIPHY_FREQ); ok {
+		freq := int64(v)
+		ifi.Frequency = &freq
+	}
+	if v, ok := a.uint32(unix.NL80211_ATTR_CHANNEL_WIDTH); ok {
+		if width, ok := nl80211ChannelWidths[v]; ok {
+			ifi.ChannelWidth = &width
+		}
+	}
+	return ifi
+}
+
+// wifiStation is the station (for a managed interface, the access point) returned by
+// NL80211_CMD_GET_STATION.
+type wifiStation struct {
+	BSSID         string
+	Signal        *int64 // dBm
+	SignalAverage *int64 // dBm
+	TxBitrate     *int64 // bit/s
+	RxBitrate     *int64 // bit/s
+	TxMCS         *int64
+	RxMCS         *int64
+	TxRetries     *int64
+	TxFailed      *int64
+	BeaconLoss    *int64
+}
+
+// parseWifiStation parses the attributes of an NL80211_CMD_GET_STATION message.
+func parseWifiStation(a nlAttrs) (wifiStation, error) {
+	var sta wifiStation
+	sta.BSSID, _ = a.mac(unix.NL80211_ATTR_MAC)
+
+	info, ok := a.nested(unix.NL80211_ATTR_STA_INFO)
+	if !ok {
+		return sta, fmt.Errorf("station %s has no info", sta.BSSID)
+	}
+
+	int8Ptr := func(t uint16) *int64 {
+		if v, ok := info.int8(t); ok {
+			p := int64(v)
+			return &p
+		}
+		return nil
+	}
+	uint32Ptr := func(t uint16) *int64 {
+		if v, ok := info.uint32(t); ok {
+			p := int64(v)
+			return &p
+		}
+		return nil
+	}
+
+	sta.Signal = int8Ptr(unix.NL80211_STA_INFO_SIGNAL)
+	sta.SignalAverage = int8Ptr(unix.NL80211_STA_INFO_SIGNAL_AVG)
+	sta.TxRetries = uint32Ptr(unix.NL80211_STA_INFO_TX_RETRIES)
+	sta.TxFailed = uint32Ptr(unix.NL80211_STA_INFO_TX_FAILED)
+	sta.BeaconLoss = uint32Ptr(unix.NL80211_STA_INFO_BEACON_LOSS)
+
+	if rate, ok := info.nested(unix.NL80211_STA_INFO_TX_BITRATE); ok {
+		sta.TxBitrate, sta.TxMCS = parseRateInfo(rate)
+	}
+	if rate, ok := info.nested(unix.NL80211_STA_INFO_RX_BITRATE); ok {
+		sta.RxBitrate, sta.RxMCS = parseRateInfo(rate)
+	}
+
+	return sta, nil
+}
+
+// parseRateInfo parses a nested NL80211_RATE_INFO_* attribute, returning the bitrate in
+// bit/s and the MCS index of whichever PHY (HT, VHT, HE or EHT) is in use.
+func parseRateInfo(a nlAttrs) (bitrate, mcs *int64) {
+	// Bitrates are in units of 100 kbit/s; the 16 bit attribute overflows above 6.5 Gbit/s.
+	if v, ok := a.uint32(unix.NL80211_RATE_INFO_BITRATE32); ok {
+		b := int64(v) * 100000
+		bitrate = &b
+	} else if v, ok := a.uint16(unix.NL80211_RATE_INFO_BITRATE); ok {
+		b := int64(v) * 100000
+		bitrate = &b
+	}
+
+	for _, t := range []uint16{
+		unix.NL80211_RATE_INFO_MCS,
+		unix.NL80211_RATE_INFO_VHT_MCS,
+		unix.NL80211_RATE_INFO_HE_MCS,
+		unix.NL80211_RATE_INFO_EHT_MCS,
+	} {
+		if v, ok := a.uint8(t); ok {
+			m := int64(v)
+			mcs = &m
+			break
+		}
+	}
+
+	return bitrate, mcs
+}
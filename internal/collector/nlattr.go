@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// nlAttrs holds netlink attribute payloads by type.
+type nlAttrs map[uint16][]byte
+
+// parseNLAttrs parses a stream of netlink attributes. The nested and byte order flags
+// are cleared from the types.
+func parseNLAttrs(b []byte) (nlAttrs, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+
+	a := make(nlAttrs, len(attrs))
+	for _, attr := range attrs {
+		a[attr.Attr.Type&nl.NLA_TYPE_MASK] = attr.Value
+	}
+	return a, nil
+}
+
+// uint64 returns an attribute as a u64.
+func (a nlAttrs) uint64(t uint16) (uint64, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 8 {
+		return 0, false
+	}
+	return nl.NativeEndian().Uint64(v), true
+}
+
+// uint32 returns an attribute as a u32.
+func (a nlAttrs) uint32(t uint16) (uint32, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 4 {
+		return 0, false
+	}
+	return nl.NativeEndian().Uint32(v), true
+}
+
+// uint16 returns an attribute as a u16.
+func (a nlAttrs) uint16(t uint16) (uint16, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 2 {
+		return 0, false
+	}
+	return nl.NativeEndian().Uint16(v), true
+}
+
+// uint8 returns an attribute as a u8.
+func (a nlAttrs) uint8(t uint16) (uint8, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 1 {
+		return 0, false
+	}
+	return v[0], true
+}
+
+// int32 returns an attribute as an s32.
+func (a nlAttrs) int32(t uint16) (int32, bool) {
+	v, ok := a.uint32(t)
+	return int32(v), ok
+}
+
+// int8 returns an attribute as an s8, as used for signal levels in dBm.
+func (a nlAttrs) int8(t uint16) (int8, bool) {
+	v, ok := a.uint8(t)
+	return int8(v), ok
+}
+
+// string returns an attribute as a string, without the NUL terminator.
+func (a nlAttrs) string(t uint16) (string, bool) {
+	v, ok := a[t]
+	if !ok {
+		return "", false
+	}
+	return unix.ByteSliceToString(v), true
+}
+
+// be16 returns an attribute as a big endian (network byte order) u16.
+func (a nlAttrs) be16(t uint16) (uint16, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(v), true
+}
+
+// be32 returns an attribute as a big endian (network byte order) u32.
+func (a nlAttrs) be32(t uint16) (uint32, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(v), true
+}
+
+// be64 returns an attribute as a big endian (network byte order) u64.
+func (a nlAttrs) be64(t uint16) (uint64, bool) {
+	v, ok := a[t]
+	if !ok || len(v) < 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(v), true
+}
+
+// ip returns an attribute as an IPv4 or IPv6 address.
+func (a nlAttrs) ip(t uint16) (net.IP, bool) {
+	v, ok := a[t]
+	if !ok || (len(v) != net.IPv4len && len(v) != net.IPv6len) {
+		return nil, false
+	}
+	return net.IP(v), true
+}
+
+// mac returns an attribute as a hardware address.
+func (a nlAttrs) mac(t uint16) (string, bool) {
+	v, ok := a[t]
+	if !ok || len(v) != 6 {
+		return "", false
+	}
+	return net.HardwareAddr(v).String(), true
+}
+
+// nested parses an attribute holding nested attributes.
+func (a nlAttrs) nested(t uint16) (nlAttrs, bool) {
+	v, ok := a[t]
+	if !ok {
+		return nil, false
+	}
+	nested, err := parseNLAttrs(v)
+	if err != nil {
+		return nil, false
+	}
+	return nested, true
+}
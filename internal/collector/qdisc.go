@@ -0,0 +1,415 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// Attributes of TCA_STATS2 (linux/gen_stats.h).
+const (
+	tcaStatsBasic = 1
+	tcaStatsQueue = 3
+	tcaStatsApp   = 4
+	tcaStatsPkt64 = 8
+)
+
+// Attributes of the cake xstats (linux/pkt_sched.h).
+const (
+	tcaCakeStatsCapacityEstimate64 = 2
+	tcaCakeStatsMemoryUsed         = 4
+	tcaCakeStatsTinStats           = 10
+
+	tcaCakeTinStatsSentPackets        = 2
+	tcaCakeTinStatsSentBytes64        = 3
+	tcaCakeTinStatsDroppedPackets     = 4
+	tcaCakeTinStatsAcksDroppedPackets = 6
+	tcaCakeTinStatsECNMarkedPackets   = 8
+	tcaCakeTinStatsBacklogBytes       = 11
+	tcaCakeTinStatsPeakDelayUs        = 18
+	tcaCakeTinStatsAvgDelayUs         = 19
+	tcaCakeTinStatsBaseDelayUs        = 20
+)
+
+// qdiscStats maps the generic statistics of qdiscs and classes to their metric (prefixed
+// with "qdisc." or "qdisc.class.").
+var qdiscStats = []struct {
+	name        string
+	description string // formatted with "qdisc" or "class"
+	unit        string
+	gauge       bool
+	value       func(tcObject) int64
+}{
+	{"bytes", "Bytes sent by a %s", "By", false, func(o tcObject) int64 { return int64(o.Bytes) }},
+	{"packets", "Packets sent by a %s", "{packets}", false, func(o tcObject) int64 { return int64(o.Packets) }},
+	{"drops", "Packets dropped by a %s", "{packets}", false, func(o tcObject) int64 { return int64(o.Drops) }},
+	{"overlimits", "Packets delayed or dropped by a %s because they exceeded its rate or limit", "{packets}", false, func(o tcObject) int64 { return int64(o.Overlimits) }},
+	{"requeues", "Packets a %s had to requeue, e.g. because the device was busy", "{packets}", false, func(o tcObject) int64 { return int64(o.Requeues) }},
+	{"backlog", "Bytes queued in a %s", "By", true, func(o tcObject) int64 { return int64(o.Backlog) }},
+	{"qlen", "Packets queued in a %s", "{packets}", true, func(o tcObject) int64 { return int64(o.Qlen) }},
+}
+
+// qdiscXstats describes the metrics of the kind specific statistics (xstats).
+var qdiscXstats = []struct {
+	name        string
+	description string
+	unit        string
+	gauge       bool
+}{
+	{"qdisc.fq_codel.ecn_marks", "Packets ECN marked by fq_codel instead of being dropped", "{packets}", false},
+	{"qdisc.fq_codel.ce_marks", "Packets CE marked by fq_codel because their sojourn time exceeded ce_threshold", "{packets}", false},
+	{"qdisc.fq_codel.drop_overlimit", "Packets dropped by fq_codel because the queue limit was reached", "{packets}", false},
+	{"qdisc.fq_codel.drop_overmemory", "Packets dropped by fq_codel because the memory limit was reached", "{packets}", false},
+	{"qdisc.fq_codel.new_flows", "Flows that became active in fq_codel", "{flows}", false},
+	{"qdisc.fq_codel.memory_usage", "Memory used by the packets queued in fq_codel", "By", true},
+	{"qdisc.cake.capacity", "Capacity estimated by cake (its configured bandwidth unless autorate is enabled)", "By/s", true},
+	{"qdisc.cake.memory_usage", "Memory used by the packets queued in cake", "By", true},
+	{"qdisc.cake.tin.bytes", "Bytes sent by a cake tin", "By", false},
+	{"qdisc.cake.tin.packets", "Packets sent by a cake tin", "{packets}", false},
+	{"qdisc.cake.tin.drops", "Packets dropped by a cake tin", "{packets}", false},
+	{"qdisc.cake.tin.ecn_marks", "Packets ECN marked by a cake tin instead of being dropped", "{packets}", false},
+	{"qdisc.cake.tin.ack_drops", "TCP ACKs dropped by the ACK filter of a cake tin", "{packets}", false},
+	{"qdisc.cake.tin.backlog", "Bytes queued in a cake tin", "By", true},
+	{"qdisc.cake.tin.delay.peak", "Peak queueing delay of a cake tin", "us", true},
+	{"qdisc.cake.tin.delay.average", "Average queueing delay of a cake tin", "us", true},
+	{"qdisc.cake.tin.delay.base", "Base (minimum) queueing delay of a cake tin", "us", true},
+	{"qdisc.class.htb.lends", "Packets an HTB class sent within its own rate", "{packets}", false},
+	{"qdisc.class.htb.borrows", "Packets an HTB class sent by borrowing from its ancestors", "{packets}", false},
+}
+
+// tcFlowKinds are the qdiscs whose classes are their flows, which are not reported: they
+// come and go with the traffic.
+var tcFlowKinds = map[string]bool{
+	"fq_codel": true,
+	"fq_pie":   true,
+	"cake":     true,
+	"sfq":      true,
+	"fq":       true,
+}
+
+// tcMultiqueueKinds are the qdiscs whose classes are the transmit queues of the interface
+// (or groups of them), each with the statistics of its child qdisc, and whose own
+// statistics are the sum of the child qdiscs. Neither is reported, as they would count the
+// traffic of the child qdiscs twice.
+var tcMultiqueueKinds = map[string]bool{
+	"mq":     true,
+	"mqprio": true,
+	"taprio": true,
+}
+
+// Qdisc collector exposes the statistics of the traffic control queueing disciplines
+// (qdiscs) and their classes, and the kind specific statistics of fq_codel, cake and HTB.
+type Qdisc struct {
+	meter metric.Meter
+}
+
+// NewQdisc creates a new Qdisc collector.
+func NewQdisc() (*Qdisc, error) {
+	return &Qdisc{
+		meter: otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+	}, nil
+}
+
+// tcObject is a qdisc or class and its statistics, as returned by RTM_GETQDISC and
+// RTM_GETTCLASS.
+type tcObject struct {
+	Index  int32
+	Kind   string // e.g. "fq_codel"
+	Handle uint32
+	Parent uint32
+
+	Bytes      uint64
+	Packets    uint64
+	Drops      uint32
+	Overlimits uint32
+	Requeues   uint32
+	Backlog    uint32 // bytes
+	Qlen       uint32 // packets
+
+	Xstats []tcXstat
+}
+
+// tcXstat is a kind specific statistic of a qdisc or class.
+type tcXstat struct {
+	name  string // the metric, e.g. "qdisc.fq_codel.ecn_marks"
+	tin   string // the cake tin, if any
+	value int64
+}
+
+// parseTCMsg parses a struct tcmsg and its trailing attributes.
+func parseTCMsg(msg []byte) (tcObject, error) {
+	if len(msg) < nl.SizeofTcMsg {
+		return tcObject{}, fmt.Errorf("tcmsg too short: %d bytes", len(msg))
+	}
+
+	native := nl.NativeEndian()
+	o := tcObject{
+		Index:  int32(native.Uint32(msg[4:8])),
+		Handle: native.Uint32(msg[8:12]),
+		Parent: native.Uint32(msg[12:16]),
+	}
+
+	a, err := parseNLAttrs(msg[nl.SizeofTcMsg:])
+	if err != nil {
+		return o, err
+	}
+	o.Kind, _ = a.string(unix.TCA_KIND)
+
+	stats, ok := a.nested(unix.TCA_STATS2)
+	if !ok {
+		return o, nil
+	}
+	// struct gnet_stats_basic holds a u64 byte and a u32 packet counter; newer kernels
+	// also report the packets as a u64 (TCA_STATS_PKT64).
+	if basic, ok := stats[tcaStatsBasic]; ok && len(basic) >= 12 {
+		o.Bytes = native.Uint64(basic[0:8])
+		o.Packets = uint64(native.Uint32(basic[8:12]))
+	}
+	if packets, ok := stats.uint64(tcaStatsPkt64); ok {
+		o.Packets = packets
+	}
+	// struct gnet_stats_queue
+	if queue, ok := stats[tcaStatsQueue]; ok && len(queue) >= 20 {
+		o.Qlen = native.Uint32(queue[0:4])
+		o.Backlog = native.Uint32(queue[4:8])
+		o.Drops = native.Uint32(queue[8:12])
+		o.Requeues = native.Uint32(queue[12:16])
+		o.Overlimits = native.Uint32(queue[16:20])
+	}
+
+	if app, ok := stats[tcaStatsApp]; ok {
+		o.Xstats = parseTCXstats(o.Kind, app)
+	}
+	return o, nil
+}
+
+// parseTCXstats parses the kind specific statistics of the qdiscs and classes that have
+// them reported.
+func parseTCXstats(kind string, b []byte) []tcXstat {
+	native := nl.NativeEndian()
+
+	switch kind {
+	case "fq_codel":
+		// struct tc_fq_codel_xstats: a u32 type, 0 for the qdisc, then its statistics.
+		if len(b) < 40 || native.Uint32(b[0:4]) != 0 {
+			return nil
+		}
+		u32 := func(off int) int64 { return int64(native.Uint32(b[off : off+4])) }
+		return []tcXstat{
+			{name: "qdisc.fq_codel.drop_overlimit", value: u32(8)},
+			{name: "qdisc.fq_codel.ecn_marks", value: u32(12)},
+			{name: "qdisc.fq_codel.new_flows", value: u32(16)},
+			{name: "qdisc.fq_codel.ce_marks", value: u32(28)},
+			{name: "qdisc.fq_codel.memory_usage", value: u32(32)},
+			{name: "qdisc.fq_codel.drop_overmemory", value: u32(36)},
+		}
+
+	case "cake":
+		a, err := parseNLAttrs(b)
+		if err != nil {
+			return nil
+		}
+		var xstats []tcXstat
+		if v, ok := a.uint64(tcaCakeStatsCapacityEstimate64); ok {
+			xstats = append(xstats, tcXstat{name: "qdisc.cake.capacity", value: int64(v)})
+		}
+		if v, ok := a.uint32(tcaCakeStatsMemoryUsed); ok {
+			xstats = append(xstats, tcXstat{name: "qdisc.cake.memory_usage", value: int64(v)})
+		}
+
+		tins, _ := a.nested(tcaCakeStatsTinStats)
+		// The tins are nested under their number, counting from 1.
+		for i := uint16(1); ; i++ {
+			tin, ok := tins.nested(i)
+			if !ok {
+				break
+			}
+			name := strconv.Itoa(int(i - 1))
+			for _, s := range []struct {
+				name string
+				attr uint16
+				u64  bool
+			}{
+				{"qdisc.cake.tin.bytes", tcaCakeTinStatsSentBytes64, true},
+				{"qdisc.cake.tin.packets", tcaCakeTinStatsSentPackets, false},
+				{"qdisc.cake.tin.drops", tcaCakeTinStatsDroppedPackets, false},
+				{"qdisc.cake.tin.ecn_marks", tcaCakeTinStatsECNMarkedPackets, false},
+				{"qdisc.cake.tin.ack_drops", tcaCakeTinStatsAcksDroppedPackets, false},
+				{"qdisc.cake.tin.backlog", tcaCakeTinStatsBacklogBytes, false},
+				{"qdisc.cake.tin.delay.peak", tcaCakeTinStatsPeakDelayUs, false},
+				{"qdisc.cake.tin.delay.average", tcaCakeTinStatsAvgDelayUs, false},
+				{"qdisc.cake.tin.delay.base", tcaCakeTinStatsBaseDelayUs, false},
+			} {
+				if s.u64 {
+					if v, ok := tin.uint64(s.attr); ok {
+						xstats = append(xstats, tcXstat{name: s.name, tin: name, value: int64(v)})
+					}
+				} else if v, ok := tin.uint32(s.attr); ok {
+					xstats = append(xstats, tcXstat{name: s.name, tin: name, value: int64(v)})
+				}
+			}
+		}
+		return xstats
+
+	case "htb":
+		// struct tc_htb_xstats, reported for classes only.
+		if len(b) < 8 {
+			return nil
+		}
+		return []tcXstat{
+			{name: "qdisc.class.htb.lends", value: int64(native.Uint32(b[0:4]))},
+			{name: "qdisc.class.htb.borrows", value: int64(native.Uint32(b[4:8]))},
+		}
+	}
+	return nil
+}
+
+// dumpTC dumps the qdiscs (RTM_GETQDISC) or classes (RTM_GETTCLASS) of an interface, or of
+// every interface if index is 0 (qdiscs only), calling fn with each message of msgType.
+func dumpTC(cmd int, msgType uint16, index int32, fn func(tcObject)) error {
+	req := nl.NewNetlinkRequest(cmd, unix.NLM_F_DUMP)
+	req.AddData(&nl.TcMsg{Family: nl.FAMILY_ALL, Ifindex: index})
+
+	var parseErr error
+	err := req.ExecuteIter(unix.NETLINK_ROUTE, msgType, func(msg []byte) bool {
+		o, err := parseTCMsg(msg)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		fn(o)
+		return true
+	})
+	// An interrupted dump is still useful; the configuration changed while it was read.
+	if err != nil && !errors.Is(err, netlink.ErrDumpInterrupted) {
+		return err
+	}
+	return parseErr
+}
+
+// Start registers the Qdisc metrics callback.
+func (c *Qdisc) Start(_ context.Context) error {
+	var observables []metric.Observable
+
+	newInstrument := func(name, description, unit string, gauge bool) (metric.Int64Observable, error) {
+		var (
+			inst metric.Int64Observable
+			err  error
+		)
+		if gauge {
+			inst, err = c.meter.Int64ObservableGauge(name, metric.WithDescription(description), metric.WithUnit(unit))
+		} else {
+			inst, err = c.meter.Int64ObservableCounter(name, metric.WithDescription(description), metric.WithUnit(unit))
+		}
+		if err != nil {
+			return nil, err
+		}
+		observables = append(observables, inst)
+		return inst, nil
+	}
+
+	qdiscMetrics := make([]metric.Int64Observable, len(qdiscStats))
+	classMetrics := make([]metric.Int64Observable, len(qdiscStats))
+	for i, s := range qdiscStats {
+		var err error
+		if qdiscMetrics[i], err = newInstrument("qdisc."+s.name, fmt.Sprintf(s.description, "qdisc"), s.unit, s.gauge); err != nil {
+			return err
+		}
+		if classMetrics[i], err = newInstrument("qdisc.class."+s.name, fmt.Sprintf(s.description, "class"), s.unit, s.gauge); err != nil {
+			return err
+		}
+	}
+
+	xstatMetrics := make(map[string]metric.Int64Observable, len(qdiscXstats))
+	for _, s := range qdiscXstats {
+		inst, err := newInstrument(s.name, s.description, s.unit, s.gauge)
+		if err != nil {
+			return err
+		}
+		xstatMetrics[s.name] = inst
+	}
+
+	_, err := c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return fmt.Errorf("failed to list links: %w", err)
+		}
+		names := make(map[int32]string, len(links))
+		for _, l := range links {
+			names[int32(l.Attrs().Index)] = l.Attrs().Name
+		}
+		name := func(index int32) string {
+			if n, ok := names[index]; ok {
+				return n
+			}
+			// Added since the links were listed.
+			return strconv.Itoa(int(index))
+		}
+
+		observe := func(obj tcObject, metrics []metric.Int64Observable) {
+			attrs := []attribute.KeyValue{
+				attribute.String("interface", name(obj.Index)),
+				attribute.String("kind", obj.Kind),
+				attribute.String("handle", netlink.HandleStr(obj.Handle)),
+				attribute.String("parent", netlink.HandleStr(obj.Parent)),
+			}
+			opt := metric.WithAttributes(attrs...)
+			for i, s := range qdiscStats {
+				o.ObserveInt64(metrics[i], s.value(obj), opt)
+			}
+			for _, x := range obj.Xstats {
+				inst, ok := xstatMetrics[x.name]
+				if !ok {
+					continue
+				}
+				if x.tin != "" {
+					o.ObserveInt64(inst, x.value, metric.WithAttributes(append(attrs, attribute.String("tin", x.tin))...))
+				} else {
+					o.ObserveInt64(inst, x.value, opt)
+				}
+			}
+		}
+
+		// Classes can only be dumped per interface; only interfaces with a qdisc have any.
+		var indexes []int32
+		seen := make(map[int32]bool)
+		err = dumpTC(unix.RTM_GETQDISC, unix.RTM_NEWQDISC, 0, func(obj tcObject) {
+			// noqueue (e.g. on loopback and virtual interfaces) never queues or counts.
+			if obj.Kind == "noqueue" {
+				return
+			}
+			if !seen[obj.Index] {
+				seen[obj.Index] = true
+				indexes = append(indexes, obj.Index)
+			}
+			if !tcMultiqueueKinds[obj.Kind] {
+				observe(obj, qdiscMetrics)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to dump qdiscs: %w", err)
+		}
+
+		for _, index := range indexes {
+			err := dumpTC(unix.RTM_GETTCLASS, unix.RTM_NEWTCLASS, index, func(obj tcObject) {
+				if !tcFlowKinds[obj.Kind] && !tcMultiqueueKinds[obj.Kind] {
+					observe(obj, classMetrics)
+				}
+			})
+			// An interface removed since its qdiscs were dumped has no classes left.
+			if err != nil && !errors.Is(err, unix.ENODEV) {
+				otel.Handle(fmt.Errorf("failed to dump classes of %s: %w", name(index), err))
+			}
+		}
+		return nil
+	}, observables...)
+	return err
+}
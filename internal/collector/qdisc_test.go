@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseTCMsg(t *testing.T) {
+	native := binary.NativeEndian
+
+	// struct gnet_stats_basic, with the u32 packet counter wrapped.
+	basic := native.AppendUint64(nil, 150000)
+	basic = native.AppendUint32(basic, 100)
+	basic = native.AppendUint32(basic, 0)
+
+	// struct gnet_stats_queue: qlen, backlog, drops, requeues, overlimits.
+	var queue []byte
+	for _, v := range []uint32{3, 4500, 7, 1, 12} {
+		queue = native.AppendUint32(queue, v)
+	}
+
+	// struct tc_fq_codel_xstats of the qdisc: type, maxpacket, drop_overlimit, ecn_mark,
+	// new_flow_count, new_flows_len, old_flows_len, ce_mark, memory_usage, drop_overmemory.
+	var xstats []byte
+	for _, v := range []uint32{0, 1514, 2, 40, 300, 1, 4, 5, 65536, 6} {
+		xstats = native.AppendUint32(xstats, v)
+	}
+
+	stats := nl.NewRtAttr(unix.TCA_STATS2|unix.NLA_F_NESTED, nil)
+	stats.AddRtAttr(tcaStatsBasic, basic)
+	stats.AddRtAttr(tcaStatsQueue, queue)
+	stats.AddRtAttr(tcaStatsApp, xstats)
+	stats.AddRtAttr(tcaStatsPkt64, nl.Uint64Attr(1<<32+100))
+
+	msg := (&nl.TcMsg{Family: nl.FAMILY_ALL, Ifindex: 2, Handle: 0x10000, Parent: 0xffffffff}).Serialize()
+	msg = append(msg, nl.NewRtAttr(unix.TCA_KIND, nl.ZeroTerminated("fq_codel")).Serialize()...)
+	msg = append(msg, stats.Serialize()...)
+
+	o, err := parseTCMsg(msg)
+	if err != nil {
+		t.Fatalf("failed to parse tcmsg: %v", err)
+	}
+
+	if o.Index != 2 || o.Kind != "fq_codel" || o.Handle != 0x10000 || o.Parent != 0xffffffff {
+		t.Errorf("expected fq_codel 1:0 of interface 2 under root, got %s %x of %d under %x", o.Kind, o.Handle, o.Index, o.Parent)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"bytes", o.Bytes, 150000},
+		{"packets", o.Packets, 1<<32 + 100},
+		{"drops", uint64(o.Drops), 7},
+		{"overlimits", uint64(o.Overlimits), 12},
+		{"requeues", uint64(o.Requeues), 1},
+		{"backlog", uint64(o.Backlog), 4500},
+		{"qlen", uint64(o.Qlen), 3},
+	} {
+		if c.got != c.want {
+			t.Errorf("expected %s %d, got %d", c.name, c.want, c.got)
+		}
+	}
+
+	wantXstats := []tcXstat{
+		{name: "qdisc.fq_codel.drop_overlimit", value: 2},
+		{name: "qdisc.fq_codel.ecn_marks", value: 40},
+		{name: "qdisc.fq_codel.new_flows", value: 300},
+		{name: "qdisc.fq_codel.ce_marks", value: 5},
+		{name: "qdisc.fq_codel.memory_usage", value: 65536},
+		{name: "qdisc.fq_codel.drop_overmemory", value: 6},
+	}
+	if !slices.Equal(o.Xstats, wantXstats) {
+		t.Errorf("expected xstats %v, got %v", wantXstats, o.Xstats)
+	}
+}
+
+func TestParseTCXstats(t *testing.T) {
+	// cake with two tins.
+	cake := nl.NewRtAttr(tcaStatsApp, nil)
+	cake.AddRtAttr(tcaCakeStatsCapacityEstimate64, nl.Uint64Attr(1250000))
+	cake.AddRtAttr(tcaCakeStatsMemoryUsed, nl.Uint32Attr(8192))
+	tins := cake.AddRtAttr(tcaCakeStatsTinStats|unix.NLA_F_NESTED, nil)
+	for i, sent := range []uint64{1000, 2000} {
+		tin := tins.AddRtAttr((i+1)|unix.NLA_F_NESTED, nil)
+		tin.AddRtAttr(tcaCakeTinStatsSentBytes64, nl.Uint64Attr(sent))
+		tin.AddRtAttr(tcaCakeTinStatsDroppedPackets, nl.Uint32Attr(uint32(i)))
+		tin.AddRtAttr(tcaCakeTinStatsAvgDelayUs, nl.Uint32Attr(250))
+	}
+
+	got := parseTCXstats("cake", cake.Serialize()[unix.SizeofRtAttr:])
+	want := []tcXstat{
+		{name: "qdisc.cake.capacity", value: 1250000},
+		{name: "qdisc.cake.memory_usage", value: 8192},
+		{name: "qdisc.cake.tin.bytes", tin: "0", value: 1000},
+		{name: "qdisc.cake.tin.drops", tin: "0", value: 0},
+		{name: "qdisc.cake.tin.delay.average", tin: "0", value: 250},
+		{name: "qdisc.cake.tin.bytes", tin: "1", value: 2000},
+		{name: "qdisc.cake.tin.drops", tin: "1", value: 1},
+		{name: "qdisc.cake.tin.delay.average", tin: "1", value: 250},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected cake xstats %v, got %v", want, got)
+	}
+
+	// struct tc_htb_xstats: lends, borrows, giants, tokens, ctokens.
+	native := binary.NativeEndian
+	var htb []byte
+	for _, v := range []uint32{212, 30, 0, 15, 15} {
+		htb = native.AppendUint32(htb, v)
+	}
+	got = parseTCXstats("htb", htb)
+	want = []tcXstat{
+		{name: "qdisc.class.htb.lends", value: 212},
+		{name: "qdisc.class.htb.borrows", value: 30},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected htb xstats %v, got %v", want, got)
+	}
+
+	// Kinds without xstats, and the statistics of an fq_codel flow (type 1).
+	if got := parseTCXstats("pfifo_fast", htb); got != nil {
+		t.Errorf("expected no pfifo_fast xstats, got %v", got)
+	}
+	if got := parseTCXstats("fq_codel", native.AppendUint32(make([]byte, 0, 40), 1)); got != nil {
+		t.Errorf("expected no fq_codel flow xstats, got %v", got)
+	}
+}
@@ -0,0 +1,477 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// routeTables names the reserved routing tables (linux/rtnetlink.h).
+var routeTables = map[int]string{
+	unix.RT_TABLE_DEFAULT: "default",
+	unix.RT_TABLE_MAIN:    "main",
+	unix.RT_TABLE_LOCAL:   "local",
+}
+
+// Route collector exposes the number of routes in the IPv4 and IPv6 routing tables, the
+// default routes, and how often the default routes changed.
+//
+// Changes are counted from rtnetlink route events, so a default route that flaps between
+// collections is counted, and from comparing the routes at each collection, which finds
+// the routes the kernel removes without an event (e.g. IPv4 routes of an interface going
+// down) and the changes made while events are unavailable.
+type Route struct {
+	meter          metric.Meter
+	procMountPoint string
+
+	mu sync.Mutex
+	// defaults holds the known default routes by IP version, or is nil until they are
+	// first listed.
+	defaults map[string][]routeEntry
+	// changes counts the default route changes by IP version.
+	changes map[string]int64
+	// generation counts the default route events applied, so that a list of the routes taken
+	// while an event was applied is not compared with the defaults the event left.
+	generation uint64
+}
+
+// NewRoute creates a new Route collector.
+func NewRoute(procMountPoint string) (*Route, error) {
+	return &Route{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		procMountPoint: procMountPoint,
+		changes:        map[string]int64{"4": 0, "6": 0},
+	}, nil
+}
+
+// routeEntry is a route, or a next hop of a multipath route.
+type routeEntry struct {
+	Version  string // "4" or "6"
+	Table    string // e.g. "main"
+	Protocol string // e.g. "kernel", "dhcp" or "static"
+	// Default is set for unicast routes to 0.0.0.0/0 or ::/0.
+	Default   bool
+	Gateway   string // empty without a gateway, e.g. on point-to-point links
+	Interface string
+	Priority  int
+}
+
+// sameRoute reports whether two entries are the same route. Only the gateway, interface
+// and priority are compared, as the table and protocol are unknown in /proc.
+func sameRoute(a, b routeEntry) bool {
+	return a.Gateway == b.Gateway && a.Interface == b.Interface && a.Priority == b.Priority
+}
+
+// listRoutes lists the routes of every table over rtnetlink.
+func listRoutes() ([]routeEntry, error) {
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	name := func(index int) string {
+		if n, ok := names[index]; ok {
+			return n
+		}
+		n := interfaceName(index)
+		names[index] = n
+		return n
+	}
+
+	var entries []routeEntry
+	for _, r := range routes {
+		entries = append(entries, routeEntries(r, name)...)
+	}
+	return entries, nil
+}
+
+// interfaceName returns the name of an interface, or its index if it does not exist.
+func interfaceName(index int) string {
+	if ifi, err := net.InterfaceByIndex(index); err == nil {
+		return ifi.Name
+	}
+	return strconv.Itoa(index)
+}
+
+// routeEntries converts a route to an entry, or to one per next hop of a multipath route.
+func routeEntries(r netlink.Route, name func(int) string) []routeEntry {
+	e := routeEntry{
+		Version:  "4",
+		Table:    routeTableName(r.Table),
+		Protocol: r.Protocol.String(),
+		Priority: r.Priority,
+	}
+	if r.Family == netlink.FAMILY_V6 {
+		e.Version = "6"
+	}
+	// Older kernels and library versions leave the destination of default routes unset.
+	prefix := 0
+	if r.Dst != nil {
+		prefix, _ = r.Dst.Mask.Size()
+	}
+	e.Default = prefix == 0 && r.Type == unix.RTN_UNICAST
+
+	if len(r.MultiPath) == 0 {
+		e.Interface = name(r.LinkIndex)
+		if r.Gw != nil {
+			e.Gateway = r.Gw.String()
+		}
+		return []routeEntry{e}
+	}
+
+	entries := make([]routeEntry, 0, len(r.MultiPath))
+	for _, hop := range r.MultiPath {
+		e.Interface = name(hop.LinkIndex)
+		e.Gateway = ""
+		if hop.Gw != nil {
+			e.Gateway = hop.Gw.String()
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// routeTableName returns the name of a routing table.
+func routeTableName(table int) string {
+	if name, ok := routeTables[table]; ok {
+		return name
+	}
+	return strconv.Itoa(table)
+}
+
+// readProcRoutes reads the routes from /proc/net/route and /proc/net/ipv6_route. Neither
+// has the protocol, and /proc/net/route only has the main table, while /proc/net/ipv6_route
+// has every table without saying which.
+func readProcRoutes(procMountPoint string) ([]routeEntry, error) {
+	var entries []routeEntry
+
+	// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT, with the
+	// addresses in hex in host byte order.
+	err := readRouteFile(procMountPoint+"/net/route", true, func(fields []string) {
+		if len(fields) < 8 {
+			return
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		priority, _ := strconv.Atoi(fields[6])
+		e := routeEntry{
+			Version:   "4",
+			Table:     "main",
+			Protocol:  "unknown",
+			Default:   fields[1] == "00000000" && fields[7] == "00000000" && flags&unix.RTF_REJECT == 0,
+			Interface: fields[0],
+			Priority:  priority,
+		}
+		if flags&unix.RTF_GATEWAY != 0 {
+			if v, err := strconv.ParseUint(fields[2], 16, 32); err == nil {
+				var b [4]byte
+				nl.NativeEndian().PutUint32(b[:], uint32(v))
+				e.Gateway = netip.AddrFrom4(b).String()
+			}
+		}
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Destination PrefixLength Source SourcePrefixLength NextHop Metric RefCnt Use Flags Iface,
+	// in hex.
+	err = readRouteFile(procMountPoint+"/net/ipv6_route", false, func(fields []string) {
+		if len(fields) < 10 {
+			return
+		}
+		flags, _ := strconv.ParseUint(fields[8], 16, 32)
+		priority, _ := strconv.ParseUint(fields[5], 16, 32)
+		e := routeEntry{
+			Version:   "6",
+			Table:     "unknown",
+			Protocol:  "unknown",
+			Default:   fields[1] == "00" && flags&unix.RTF_REJECT == 0,
+			Interface: fields[9],
+			Priority:  int(priority),
+		}
+		if flags&unix.RTF_GATEWAY != 0 {
+			if b, err := hex.DecodeString(fields[4]); err == nil && len(b) == 16 {
+				e.Gateway = netip.AddrFrom16([16]byte(b)).String()
+			}
+		}
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readRouteFile calls fn with the fields of each line of a routing table file.
+func readRouteFile(path string, header bool, fn func([]string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if header {
+		scanner.Scan()
+	}
+	for scanner.Scan() {
+		fn(strings.Fields(scanner.Text()))
+	}
+	return scanner.Err()
+}
+
+// list lists the routes over rtnetlink, falling back to /proc.
+func (c *Route) list() ([]routeEntry, error) {
+	entries, netlinkErr := listRoutes()
+	if netlinkErr == nil {
+		return entries, nil
+	}
+	entries, procErr := readProcRoutes(c.procMountPoint)
+	if procErr != nil {
+		return nil, fmt.Errorf("failed to read routes: %w, %w", netlinkErr, procErr)
+	}
+	return entries, nil
+}
+
+// currentGeneration returns the generation to pass to record for a list of the routes
+// taken after it.
+func (c *Route) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// record compares the default routes with the known ones, counting a change for each IP
+// version whose default routes differ, and replaces them. If a route event was applied
+// since generation, the entries may predate it, so they are left for the next collection
+// to compare.
+func (c *Route) record(entries []routeEntry, generation uint64) {
+	defaults := map[string][]routeEntry{"4": nil, "6": nil}
+	for _, e := range entries {
+		if e.Default {
+			defaults[e.Version] = append(defaults[e.Version], e)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+	if c.defaults != nil {
+		for version, current := range defaults {
+			if !routesEqual(c.defaults[version], current) {
+				c.changes[version]++
+			}
+		}
+	}
+	c.defaults = defaults
+}
+
+// routesEqual reports whether two lists hold the same routes, in any order.
+func routesEqual(a, b []routeEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, e := range a {
+		if !slices.ContainsFunc(b, func(f routeEntry) bool { return sameRoute(e, f) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleUpdate applies a route event to the known default routes, counting a change if
+// they changed.
+func (c *Route) handleUpdate(u netlink.RouteUpdate) {
+	entries := routeEntries(u.Route, interfaceName)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Until the routes are first listed, there is nothing to compare with.
+	if c.defaults == nil {
+		return
+	}
+
+	changed := make(map[string]bool)
+	for _, e := range entries {
+		if !e.Default {
+			continue
+		}
+		current := c.defaults[e.Version]
+		i := slices.IndexFunc(current, func(d routeEntry) bool { return sameRoute(d, e) })
+
+		switch u.Type {
+		case unix.RTM_NEWROUTE:
+			// A replaced route is only reported with its replacement, which has the same
+			// table and priority.
+			if u.NlFlags&unix.NLM_F_REPLACE != 0 {
+				n := len(current)
+				current = slices.DeleteFunc(current, func(d routeEntry) bool {
+					return d.Table == e.Table && d.Priority == e.Priority && !sameRoute(d, e)
+				})
+				if len(current) != n {
+					changed[e.Version] = true
+				}
+			}
+			if i < 0 {
+				current = append(current, e)
+				changed[e.Version] = true
+			}
+		case unix.RTM_DELROUTE:
+			if i >= 0 {
+				current = slices.Delete(current, i, i+1)
+				changed[e.Version] = true
+			}
+		}
+		c.defaults[e.Version] = current
+	}
+
+	for version := range changed {
+		c.changes[version]++
+	}
+	if slices.ContainsFunc(entries, func(e routeEntry) bool { return e.Default }) {
+		c.generation++
+	}
+}
+
+// subscribe counts the default route changes from the RTNLGRP_IPV4_ROUTE and
+// RTNLGRP_IPV6_ROUTE multicast groups until ctx is cancelled. When the subscription is
+// unavailable, changes are only found at each collection.
+func (c *Route) subscribe(ctx context.Context) {
+	ch := make(chan netlink.RouteUpdate)
+	if err := netlink.RouteSubscribeWithOptions(ch, ctx.Done(), netlink.RouteSubscribeOptions{
+		// Receiving fails once ctx is cancelled and the socket is closed.
+		ErrorCallback: func(err error) {
+			if ctx.Err() == nil {
+				otel.Handle(err)
+			}
+		},
+	}); err != nil {
+		otel.Handle(fmt.Errorf("failed to subscribe to route updates: %w", err))
+		return
+	}
+
+	// Seed the default routes after subscribing so no change is missed; otherwise, the
+	// first collection does.
+	generation := c.currentGeneration()
+	if entries, err := c.list(); err == nil {
+		c.record(entries, generation)
+	}
+
+	// The channel is closed when ctx is cancelled or the subscription fails.
+	go func() {
+		for u := range ch {
+			c.handleUpdate(u)
+		}
+	}()
+}
+
+// Start registers the Route metrics callbacks and subscribes to route events until ctx is
+// cancelled.
+func (c *Route) Start(ctx context.Context) error {
+	countMetric, err := c.meter.Int64ObservableGauge(
+		"route.count",
+		metric.WithDescription("Number of routes by routing table and protocol"),
+		metric.WithUnit("{routes}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	defaultMetric, err := c.meter.Int64ObservableGauge(
+		"route.default.info",
+		metric.WithDescription("Default routes, with their gateway and interface (always 1)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	defaultCountMetric, err := c.meter.Int64ObservableGauge(
+		"route.default.count",
+		metric.WithDescription("Number of default routes, including 0 when there is none"),
+		metric.WithUnit("{routes}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	changesMetric, err := c.meter.Int64ObservableCounter(
+		"route.default.changes",
+		metric.WithDescription("Times the default routes were found to have changed"),
+		metric.WithUnit("{changes}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		generation := c.currentGeneration()
+		entries, err := c.list()
+		if err != nil {
+			return err
+		}
+		c.record(entries, generation)
+
+		type countKey struct{ version, table, protocol string }
+		counts := make(map[countKey]int64)
+		defaults := map[string]int64{"4": 0, "6": 0}
+		for _, e := range entries {
+			counts[countKey{e.Version, e.Table, e.Protocol}]++
+
+			if e.Default {
+				defaults[e.Version]++
+				o.ObserveInt64(defaultMetric, 1, metric.WithAttributes(
+					attribute.String("ip.version", e.Version),
+					attribute.String("table", e.Table),
+					attribute.String("gateway", e.Gateway),
+					attribute.String("interface", e.Interface),
+					attribute.Int("priority", e.Priority),
+				))
+			}
+		}
+		for k, v := range counts {
+			o.ObserveInt64(countMetric, v, metric.WithAttributes(
+				attribute.String("ip.version", k.version),
+				attribute.String("table", k.table),
+				attribute.String("protocol", k.protocol),
+			))
+		}
+		for version, v := range defaults {
+			o.ObserveInt64(defaultCountMetric, v, metric.WithAttributes(attribute.String("ip.version", version)))
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for version, v := range c.changes {
+			o.ObserveInt64(changesMetric, v, metric.WithAttributes(attribute.String("ip.version", version)))
+		}
+		return nil
+	}, countMetric, defaultMetric, defaultCountMetric, changesMetric)
+	if err != nil {
+		return err
+	}
+
+	c.subscribe(ctx)
+	return nil
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestReadProcRoutes(t *testing.T) {
+	entries, err := readProcRoutes("testdata/proc")
+	if err != nil {
+		t.Fatalf("failed to read routes: %v", err)
+	}
+
+	counts := map[string]int{}
+	var defaults []routeEntry
+	for _, e := range entries {
+		counts[e.Version]++
+		if e.Default {
+			defaults = append(defaults, e)
+		}
+	}
+	if counts["4"] != 4 || counts["6"] != 8 {
+		t.Errorf("expected 4 IPv4 and 8 IPv6 routes, got %v", counts)
+	}
+
+	// The IPv6 unreachable route to ::/0 on lo is not a default route.
+	want := []routeEntry{
+		{Version: "4", Table: "main", Protocol: "unknown", Default: true, Gateway: "192.0.2.1", Interface: "eth0", Priority: 0},
+		{Version: "4", Table: "main", Protocol: "unknown", Default: true, Gateway: "192.168.1.1", Interface: "wlan0", Priority: 600},
+		{Version: "6", Table: "unknown", Protocol: "unknown", Default: true, Gateway: "fd00::1", Interface: "eth0", Priority: 1024},
+	}
+	if !routesEqual(defaults, want) {
+		t.Errorf("expected default routes %+v, got %+v", want, defaults)
+	}
+}
+
+func TestRouteDefaultChanges(t *testing.T) {
+	c, err := NewRoute("testdata/proc")
+	if err != nil {
+		t.Fatalf("failed to create route collector: %v", err)
+	}
+
+	eth0 := routeEntry{Version: "4", Table: "main", Protocol: "dhcp", Default: true, Gateway: "192.0.2.1", Interface: "eth0", Priority: 100}
+	wlan0 := routeEntry{Version: "4", Table: "main", Protocol: "dhcp", Default: true, Gateway: "192.168.1.1", Interface: "wlan0", Priority: 600}
+	v6 := routeEntry{Version: "6", Table: "main", Protocol: "ra", Default: true, Gateway: "fe80::1", Interface: "eth0", Priority: 1024}
+	connected := routeEntry{Version: "4", Table: "main", Protocol: "kernel", Interface: "eth0"}
+
+	// The same routes read from /proc, without their protocol.
+	eth0Proc, wlan0Proc := eth0, wlan0
+	eth0Proc.Protocol, wlan0Proc.Protocol = "unknown", "unknown"
+
+	for _, entries := range [][]routeEntry{
+		{eth0, wlan0, v6, connected}, // The first collection only records the routes
+		{wlan0, eth0, connected, v6}, // Same routes, in another order
+		{wlan0, v6},                  // eth0 lost its default route
+		{wlan0, v6},                  // No change
+		{eth0, wlan0},                // eth0 is back, the IPv6 default route is gone
+		{eth0Proc, wlan0Proc},        // No change, read from /proc
+	} {
+		c.record(entries, c.currentGeneration())
+	}
+
+	if c.changes["4"] != 2 || c.changes["6"] != 1 {
+		t.Errorf("expected 2 IPv4 and 1 IPv6 default route changes, got %v", c.changes)
+	}
+}
+
+func TestRouteUpdates(t *testing.T) {
+	c, err := NewRoute("testdata/proc")
+	if err != nil {
+		t.Fatalf("failed to create route collector: %v", err)
+	}
+
+	// Interface 4242 does not exist, so it is named after its index.
+	route := func(gateway string, priority int) netlink.Route {
+		r := netlink.Route{
+			LinkIndex: 4242,
+			Gw:        net.ParseIP(gateway),
+			Priority:  priority,
+			Table:     unix.RT_TABLE_MAIN,
+			Type:      unix.RTN_UNICAST,
+			Protocol:  unix.RTPROT_DHCP,
+			Family:    netlink.FAMILY_V4,
+		}
+		if r.Gw.To4() == nil {
+			r.Family = netlink.FAMILY_V6
+		}
+		return r
+	}
+	update := func(msgType, flags uint16, r netlink.Route) {
+		c.handleUpdate(netlink.RouteUpdate{Type: msgType, NlFlags: flags, Route: r})
+	}
+
+	// Events before the routes are listed are not counted.
+	update(unix.RTM_DELROUTE, 0, route("192.0.2.1", 100))
+	c.record([]routeEntry{{Version: "4", Table: "main", Protocol: "dhcp", Default: true, Gateway: "192.0.2.1", Interface: "4242", Priority: 100}}, c.currentGeneration())
+
+	update(unix.RTM_DELROUTE, 0, route("192.0.2.1", 100)) // Flaps between collections
+	update(unix.RTM_NEWROUTE, 0, route("192.0.2.1", 100))
+	update(unix.RTM_NEWROUTE, 0, route("192.0.2.1", 100))                    // Already known
+	update(unix.RTM_NEWROUTE, unix.NLM_F_REPLACE, route("192.0.2.254", 100)) // New gateway
+
+	connected := route("192.0.2.1", 0)
+	connected.Dst = &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	update(unix.RTM_NEWROUTE, 0, connected)
+
+	update(unix.RTM_NEWROUTE, 0, route("fd00::1", 1024))
+
+	if c.changes["4"] != 3 || c.changes["6"] != 1 {
+		t.Errorf("expected 3 IPv4 and 1 IPv6 default route changes, got %v", c.changes)
+	}
+	want := []routeEntry{{Version: "4", Table: "main", Protocol: "dhcp", Default: true, Gateway: "192.0.2.254", Interface: "4242", Priority: 100}}
+	if !routesEqual(c.defaults["4"], want) {
+		t.Errorf("expected default routes %+v, got %+v", want, c.defaults["4"])
+	}
+
+	// The collection agrees with the events, so finds no change.
+	c.record(append(want, routeEntry{Version: "6", Table: "main", Protocol: "dhcp", Default: true, Gateway: "fd00::1", Interface: "4242", Priority: 1024}), c.currentGeneration())
+	if c.changes["4"] != 3 || c.changes["6"] != 1 {
+		t.Errorf("expected no more default route changes, got %v", c.changes)
+	}
+}
+
+func TestRouteUpdateDuringList(t *testing.T) {
+	c, err := NewRoute("testdata/proc")
+	if err != nil {
+		t.Fatalf("failed to create route collector: %v", err)
+	}
+
+	old := routeEntry{Version: "4", Table: "main", Protocol: "dhcp", Default: true, Gateway: "192.0.2.1", Interface: "4242", Priority: 100}
+	replaced := old
+	replaced.Gateway = "192.0.2.254"
+
+	c.record([]routeEntry{old}, c.currentGeneration())
+
+	// The default route is replaced after the collection listed the routes, but before
+	// it recorded them.
+	generation := c.currentGeneration()
+	c.handleUpdate(netlink.RouteUpdate{Type: unix.RTM_NEWROUTE, NlFlags: unix.NLM_F_REPLACE, Route: netlink.Route{
+		LinkIndex: 4242,
+		Gw:        net.ParseIP("192.0.2.254"),
+		Priority:  100,
+		Table:     unix.RT_TABLE_MAIN,
+		Type:      unix.RTN_UNICAST,
+		Protocol:  unix.RTPROT_DHCP,
+		Family:    netlink.FAMILY_V4,
+	}})
+	c.record([]routeEntry{old}, generation)
+
+	// The next collection lists the replaced route.
+	c.record([]routeEntry{replaced}, c.currentGeneration())
+
+	if c.changes["4"] != 1 {
+		t.Errorf("expected 1 IPv4 default route change, got %d", c.changes["4"])
+	}
+	if !routesEqual(c.defaults["4"], []routeEntry{replaced}) {
+		t.Errorf("expected default routes %+v, got %+v", []routeEntry{replaced}, c.defaults["4"])
+	}
+}
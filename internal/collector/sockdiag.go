@@ -0,0 +1,217 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	sizeofInetDiagReqV2 = 56
+	sizeofInetDiagMsg   = 72
+)
+
+// inetDiagReqV2 is a struct inet_diag_req_v2 (linux/inet_diag.h) requesting a dump
+// of every socket of a protocol and family.
+type inetDiagReqV2 struct {
+	family   uint8
+	protocol uint8
+	ext      uint8
+	states   uint32
+}
+
+func (r *inetDiagReqV2) Len() int { return sizeofInetDiagReqV2 }
+
+func (r *inetDiagReqV2) Serialize() []byte {
+	b := make([]byte, sizeofInetDiagReqV2)
+	b[0] = r.family
+	b[1] = r.protocol
+	b[2] = r.ext
+	nl.NativeEndian().PutUint32(b[4:8], r.states)
+	// The remaining inet_diag_sockid is left zeroed, matching every socket.
+	return b
+}
+
+// inetDiagSocket is a socket returned by a sock_diag dump.
+type inetDiagSocket struct {
+	Family       uint8
+	State        uint8
+	LocalAddress net.IP
+	LocalPort    uint16
+	Cookie       uint64 // identifies the socket for as long as it exists
+	RQueue       uint32
+	WQueue       uint32
+	Attrs        []syscall.NetlinkRouteAttr
+}
+
+// dumpInetDiag requests every socket of the given protocol and family in one of the
+// states (a bitmask of 1<<state) over NETLINK_SOCK_DIAG, calling fn for each one.
+// ext is a bitmask of the INET_DIAG_* extensions to include as attributes.
+func dumpInetDiag(family, protocol, ext uint8, states uint32, fn func(*inetDiagSocket)) error {
+	req := nl.NewNetlinkRequest(nl.SOCK_DIAG_BY_FAMILY, unix.NLM_F_DUMP)
+	req.AddData(&inetDiagReqV2{
+		family:   family,
+		protocol: protocol,
+		ext:      ext,
+		states:   states,
+	})
+
+	var parseErr error
+	err := req.ExecuteIter(unix.NETLINK_INET_DIAG, nl.SOCK_DIAG_BY_FAMILY, func(msg []byte) bool {
+		s, err := parseInetDiagMsg(msg)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		fn(s)
+		return true
+	})
+	// An interrupted dump is still useful; the table changed while it was read.
+	if err != nil && !errors.Is(err, netlink.ErrDumpInterrupted) {
+		return err
+	}
+	return parseErr
+}
+
+// parseInetDiagMsg parses a struct inet_diag_msg and its trailing attributes.
+func parseInetDiagMsg(msg []byte) (*inetDiagSocket, error) {
+	if len(msg) < sizeofInetDiagMsg {
+		return nil, fmt.Errorf("inet_diag_msg too short: %d bytes", len(msg))
+	}
+
+	s := &inetDiagSocket{
+		Family:    msg[0],
+		State:     msg[1],
+		LocalPort: binary.BigEndian.Uint16(msg[4:6]),
+		Cookie:    uint64(nl.NativeEndian().Uint32(msg[48:52]))<<32 | uint64(nl.NativeEndian().Uint32(msg[44:48])),
+		RQueue:    nl.NativeEndian().Uint32(msg[56:60]),
+		WQueue:    nl.NativeEndian().Uint32(msg[60:64]),
+	}
+
+	switch s.Family {
+	case unix.AF_INET:
+		s.LocalAddress = net.IP(append([]byte(nil), msg[8:12]...))
+	case unix.AF_INET6:
+		s.LocalAddress = net.IP(append([]byte(nil), msg[8:24]...))
+	}
+
+	attrs, err := nl.ParseRouteAttr(msg[sizeofInetDiagMsg:])
+	if err != nil {
+		return nil, err
+	}
+	s.Attrs = attrs
+
+	return s, nil
+}
+
+const (
+	sizeofUnixDiagReq = 24
+	sizeofUnixDiagMsg = 16
+
+	// unixDiagShowName and unixDiagShowRQLen are UDIAG_SHOW_NAME and UDIAG_SHOW_RQLEN.
+	unixDiagShowName  = 0x01
+	unixDiagShowRQLen = 0x10
+
+	// unixDiagName and unixDiagRQLen are the UNIX_DIAG_NAME and UNIX_DIAG_RQLEN attributes.
+	unixDiagName  = 0
+	unixDiagRQLen = 4
+)
+
+// unixDiagReq is a struct unix_diag_req (linux/unix_diag.h) requesting a dump of
+// every Unix domain socket in one of the states.
+type unixDiagReq struct {
+	states uint32
+	show   uint32
+}
+
+func (r *unixDiagReq) Len() int { return sizeofUnixDiagReq }
+
+func (r *unixDiagReq) Serialize() []byte {
+	b := make([]byte, sizeofUnixDiagReq)
+	b[0] = unix.AF_UNIX
+	nl.NativeEndian().PutUint32(b[4:8], r.states)
+	nl.NativeEndian().PutUint32(b[12:16], r.show)
+	return b
+}
+
+// unixDiagSocket is a Unix domain socket returned by a sock_diag dump.
+type unixDiagSocket struct {
+	Type   uint8
+	State  uint8
+	Inode  uint32
+	Path   string
+	RQueue uint32
+	WQueue uint32
+}
+
+// dumpUnixDiag requests every Unix domain socket in one of the states (a bitmask of
+// 1<<state) over NETLINK_SOCK_DIAG, calling fn for each one. The path and queue
+// lengths are always requested.
+func dumpUnixDiag(states uint32, fn func(*unixDiagSocket)) error {
+	req := nl.NewNetlinkRequest(nl.SOCK_DIAG_BY_FAMILY, unix.NLM_F_DUMP)
+	req.AddData(&unixDiagReq{
+		states: states,
+		show:   unixDiagShowName | unixDiagShowRQLen,
+	})
+
+	var parseErr error
+	err := req.ExecuteIter(unix.NETLINK_INET_DIAG, nl.SOCK_DIAG_BY_FAMILY, func(msg []byte) bool {
+		s, err := parseUnixDiagMsg(msg)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		fn(s)
+		return true
+	})
+	if err != nil && !errors.Is(err, netlink.ErrDumpInterrupted) {
+		return err
+	}
+	return parseErr
+}
+
+// parseUnixDiagMsg parses a struct unix_diag_msg and its trailing attributes.
+func parseUnixDiagMsg(msg []byte) (*unixDiagSocket, error) {
+	if len(msg) < sizeofUnixDiagMsg {
+		return nil, fmt.Errorf("unix_diag_msg too short: %d bytes", len(msg))
+	}
+
+	s := &unixDiagSocket{
+		Type:  msg[1],
+		State: msg[2],
+		Inode: nl.NativeEndian().Uint32(msg[4:8]),
+	}
+
+	attrs, err := nl.ParseRouteAttr(msg[sizeofUnixDiagMsg:])
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		switch a.Attr.Type {
+		case unixDiagName:
+			s.Path = unixSocketPath(a.Value)
+		case unixDiagRQLen:
+			if len(a.Value) >= 8 {
+				s.RQueue = nl.NativeEndian().Uint32(a.Value[0:4])
+				s.WQueue = nl.NativeEndian().Uint32(a.Value[4:8])
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// unixSocketPath formats a socket address, writing abstract names with a leading "@"
+// as ss(8) and /proc/net/unix do.
+func unixSocketPath(b []byte) string {
+	if len(b) > 0 && b[0] == 0 {
+		return "@" + string(b[1:])
+	}
+	return unix.ByteSliceToString(b)
+}
@@ -3,29 +3,56 @@ package collector
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/prometheus/procfs"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
+// softnetBacklogWidth is the number of columns in /proc/net/softnet_stat from Linux 5.14,
+// which added the backlog length.
+const softnetBacklogWidth = 13
+
 // Softnet collector exposes softnet statistics (kernel packet processing).
 type Softnet struct {
-	meter metric.Meter
-	fs    procfs.FS
+	meter  metric.Meter
+	fs     procfs.FS
+	perCPU bool
+}
+
+// SoftnetOption configures the Softnet collector.
+type SoftnetOption func(*Softnet) error
+
+// WithSoftnetPerCPU reports the statistics of each CPU under a "cpu" attribute instead
+// of summing them.
+func WithSoftnetPerCPU(perCPU bool) SoftnetOption {
+	return func(c *Softnet) error {
+		c.perCPU = perCPU
+		return nil
+	}
 }
 
 // NewSoftnet creates a new Softnet collector.
-func NewSoftnet(procMountPoint string) (*Softnet, error) {
+func NewSoftnet(procMountPoint string, opts ...SoftnetOption) (*Softnet, error) {
 	fs, err := procfs.NewFS(procMountPoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 
-	return &Softnet{
+	c := &Softnet{
 		meter: otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
 		fs:    fs,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
 }
 
 // Start registers the Softnet metrics callbacks.
@@ -54,29 +81,81 @@ func (c *Softnet) Start(ctx context.Context) error {
 		return err
 	}
 
+	receivedRPS, err := c.meter.Int64ObservableCounter(
+		"softnet.received_rps",
+		metric.WithDescription("Number of times a CPU was woken up by RPS to process packets"),
+	)
+	if err != nil {
+		return err
+	}
+
+	flowLimit, err := c.meter.Int64ObservableCounter(
+		"softnet.flow_limit",
+		metric.WithDescription("Number of times the flow limit was reached"),
+	)
+	if err != nil {
+		return err
+	}
+
+	backlog, err := c.meter.Int64ObservableGauge(
+		"softnet.backlog",
+		metric.WithDescription("Number of packets waiting in the softnet backlog"),
+		metric.WithUnit("{packets}"),
+	)
+	if err != nil {
+		return err
+	}
+
 	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
 		stats, err := c.fs.NetSoftnetStat()
 		if err != nil {
 			return fmt.Errorf("failed to read net softnet stats: %w", err)
 		}
 
-		// Softnet stats are per-CPU. We should probably sum them up for a global view,
-		// or expose per cpu?
-		// Global view is usually sufficient for "is my network stack overloaded?".
-		var totalProcessed, totalDropped, totalSqueezed int64
+		// Softnet stats are per-CPU. The global view is usually sufficient for "is my
+		// network stack overloaded?", while the per-CPU view shows a single saturated core.
+		if c.perCPU {
+			for _, cpu := range stats {
+				attrs := metric.WithAttributes(attribute.String("cpu", strconv.FormatUint(uint64(cpu.Index), 10)))
+				o.ObserveInt64(processed, int64(cpu.Processed), attrs)
+				o.ObserveInt64(dropped, int64(cpu.Dropped), attrs)
+				o.ObserveInt64(squeezed, int64(cpu.TimeSqueezed), attrs)
+				o.ObserveInt64(receivedRPS, int64(cpu.ReceivedRps), attrs)
+				o.ObserveInt64(flowLimit, int64(cpu.FlowLimitCount), attrs)
+				if cpu.Width >= softnetBacklogWidth {
+					o.ObserveInt64(backlog, int64(cpu.SoftnetBacklogLen), attrs)
+				}
+			}
+			return nil
+		}
+
+		var totalProcessed, totalDropped, totalSqueezed, totalReceivedRPS, totalFlowLimit, totalBacklog int64
+		hasBacklog := len(stats) > 0
 
 		for _, cpu := range stats {
 			totalProcessed += int64(cpu.Processed)
 			totalDropped += int64(cpu.Dropped)
 			totalSqueezed += int64(cpu.TimeSqueezed)
+			totalReceivedRPS += int64(cpu.ReceivedRps)
+			totalFlowLimit += int64(cpu.FlowLimitCount)
+			totalBacklog += int64(cpu.SoftnetBacklogLen)
+			// The backlog length is only reported from Linux 5.14.
+			if cpu.Width < softnetBacklogWidth {
+				hasBacklog = false
+			}
 		}
 
 		o.ObserveInt64(processed, totalProcessed)
 		o.ObserveInt64(dropped, totalDropped)
 		o.ObserveInt64(squeezed, totalSqueezed)
+		o.ObserveInt64(receivedRPS, totalReceivedRPS)
+		o.ObserveInt64(flowLimit, totalFlowLimit)
+		if hasBacklog {
+			o.ObserveInt64(backlog, totalBacklog)
+		}
 
 		return nil
-	}, processed, dropped, squeezed)
+	}, processed, dropped, squeezed, receivedRPS, flowLimit, backlog)
 
 	return err
 }
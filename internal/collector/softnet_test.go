@@ -44,8 +44,9 @@ func TestSoftnet(t *testing.T) {
 		return metricdata.Metrics{}
 	}
 
-	// Fixture Softnet: 2 CPUs. Each: processed=100 (0x64), dropped=1 (0x1), squeezed=2 (0x2)
-	// Total: processed=200, dropped=2, squeezed=4
+	// Fixture Softnet: 2 CPUs (0 and 2). Each: processed=100 (0x64), dropped=1 (0x1), squeezed=2 (0x2)
+	// CPU 0: received_rps=5, flow_limit=1, backlog=3. CPU 2: received_rps=7, flow_limit=0, backlog=0
+	// Total: processed=200, dropped=2, squeezed=4, received_rps=12, flow_limit=1, backlog=3
 
 	// Check softnet.processed (Sum)
 	m := findMetric("softnet.processed")
@@ -61,4 +62,91 @@ func TestSoftnet(t *testing.T) {
 	} else {
 		t.Error("softnet.processed not found")
 	}
+
+	// Check softnet.received_rps (Sum)
+	m = findMetric("softnet.received_rps")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("softnet.received_rps is not Sum[int64], got %T", m.Data)
+		} else {
+			if len(sum.DataPoints) > 0 && sum.DataPoints[0].Value != 12 {
+				t.Errorf("softnet.received_rps = %d, want 12", sum.DataPoints[0].Value)
+			}
+		}
+	} else {
+		t.Error("softnet.received_rps not found")
+	}
+
+	// Check softnet.backlog (Gauge)
+	m = findMetric("softnet.backlog")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("softnet.backlog is not Gauge[int64], got %T", m.Data)
+		} else {
+			if len(gauge.DataPoints) > 0 && gauge.DataPoints[0].Value != 3 {
+				t.Errorf("softnet.backlog = %d, want 3", gauge.DataPoints[0].Value)
+			}
+		}
+	} else {
+		t.Error("softnet.backlog not found")
+	}
+}
+
+func TestSoftnetPerCPU(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewSoftnet(procPath, WithSoftnetPerCPU(true))
+	if err != nil {
+		t.Fatalf("failed to create softnet collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Check softnet.received_rps (Sum) per CPU
+	m := findMetric("softnet.received_rps")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("softnet.received_rps is not Sum[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{"0": 5, "2": 7}
+			if len(sum.DataPoints) != len(want) {
+				t.Errorf("expected %d softnet.received_rps data points, got %d", len(want), len(sum.DataPoints))
+			}
+			for _, dp := range sum.DataPoints {
+				cpu, _ := dp.Attributes.Value("cpu")
+				if dp.Value != want[cpu.AsString()] {
+					t.Errorf("softnet.received_rps cpu %s = %d, want %d", cpu.AsString(), dp.Value, want[cpu.AsString()])
+				}
+			}
+		}
+	} else {
+		t.Error("softnet.received_rps not found")
+	}
 }
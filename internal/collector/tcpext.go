@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// TCPExt collector exposes extended TCP statistics from the TcpExt section of /proc/net/netstat.
+type TCPExt struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+}
+
+// NewTCPExt creates a new TCPExt collector.
+func NewTCPExt(procMountPoint string) (*TCPExt, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &TCPExt{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+	}, nil
+}
+
+// Start registers the TCPExt metrics callbacks.
+func (c *TCPExt) Start(ctx context.Context) error {
+	listenDrops, err := c.meter.Int64ObservableCounter(
+		"tcp.listen.drops",
+		metric.WithDescription("TCP connection attempts dropped by listening sockets"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	syncookies, err := c.meter.Int64ObservableCounter(
+		"tcp.syncookies",
+		metric.WithDescription("TCP SYN cookies sent, received and failed"),
+		metric.WithUnit("{cookie}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	timeouts, err := c.meter.Int64ObservableCounter(
+		"tcp.timeouts",
+		metric.WithDescription("TCP timeouts"),
+		metric.WithUnit("{timeout}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	aborts, err := c.meter.Int64ObservableCounter(
+		"tcp.aborts",
+		metric.WithDescription("TCP connections aborted"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	drops, err := c.meter.Int64ObservableCounter(
+		"tcp.drops",
+		metric.WithDescription("TCP segments dropped"),
+		metric.WithUnit("{segment}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	receiveQueue, err := c.meter.Int64ObservableCounter(
+		"tcp.receive_queue.events",
+		metric.WithDescription("TCP receive queue pruning and out-of-order queueing events"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// TcpExt keys mapped to the instrument and attribute they are reported under.
+	counters := []struct {
+		key        string
+		instrument metric.Int64ObservableCounter
+		attr       attribute.KeyValue
+	}{
+		{"ListenOverflows", listenDrops, attribute.String("reason", "overflow")},
+		{"SyncookiesSent", syncookies, attribute.String("event", "sent")},
+		{"SyncookiesRecv", syncookies, attribute.String("event", "received")},
+		{"SyncookiesFailed", syncookies, attribute.String("event", "failed")},
+		{"TCPTimeouts", timeouts, attribute.String("event", "retransmission")},
+		{"TCPAbortOnMemory", aborts, attribute.String("reason", "memory")},
+		{"TCPBacklogDrop", drops, attribute.String("reason", "backlog")},
+		{"PruneCalled", receiveQueue, attribute.String("event", "prune")},
+		{"TCPOFOQueue", receiveQueue, attribute.String("event", "out_of_order")},
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		netstat, err := readNetNetstat(c.procMountPoint)
+		if err != nil {
+			return fmt.Errorf("failed to read net netstat: %w", err)
+		}
+
+		tcpExt := netstat["TcpExt"]
+		for _, counter := range counters {
+			if v, ok := tcpExt[counter.key]; ok {
+				o.ObserveInt64(counter.instrument, v, metric.WithAttributes(counter.attr))
+			}
+		}
+
+		// The kernel counts every overflow in ListenDrops as well, so only the remainder
+		// is reported to keep the reasons disjoint.
+		if v, ok := tcpExt["ListenDrops"]; ok {
+			o.ObserveInt64(listenDrops, max(v-tcpExt["ListenOverflows"], 0), metric.WithAttributes(attribute.String("reason", "other")))
+		}
+
+		return nil
+	}, listenDrops, syncookies, timeouts, aborts, drops, receiveQueue)
+
+	return err
+}
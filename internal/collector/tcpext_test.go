@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTCPExt(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewTCPExt(procPath)
+	if err != nil {
+		t.Fatalf("failed to create tcpext collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture TcpExt: ListenOverflows=11, ListenDrops=13, SyncookiesSent=7, SyncookiesRecv=6,
+	// SyncookiesFailed=1, TCPTimeouts=21, TCPAbortOnMemory=2, TCPBacklogDrop=8, PruneCalled=3, TCPOFOQueue=42
+
+	// Check tcp.listen.drops (Sum)
+	m := findMetric("tcp.listen.drops")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("tcp.listen.drops is not Sum[int64], got %T", m.Data)
+		} else {
+			// ListenDrops includes the overflows, so 13-11 are reported as other.
+			want := map[string]int64{"overflow": 11, "other": 2}
+			for _, dp := range sum.DataPoints {
+				reason, _ := dp.Attributes.Value("reason")
+				if dp.Value != want[reason.AsString()] {
+					t.Errorf("tcp listen drops for %s = %d, want %d", reason.AsString(), dp.Value, want[reason.AsString()])
+				}
+				delete(want, reason.AsString())
+			}
+			if len(want) != 0 {
+				t.Errorf("tcp listen drops data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("tcp.listen.drops not found")
+	}
+
+	// Check tcp.syncookies (Sum)
+	m = findMetric("tcp.syncookies")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("tcp.syncookies is not Sum[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{"sent": 7, "received": 6, "failed": 1}
+			for _, dp := range sum.DataPoints {
+				event, _ := dp.Attributes.Value("event")
+				if dp.Value != want[event.AsString()] {
+					t.Errorf("tcp syncookies %s = %d, want %d", event.AsString(), dp.Value, want[event.AsString()])
+				}
+				delete(want, event.AsString())
+			}
+			if len(want) != 0 {
+				t.Errorf("tcp syncookies data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("tcp.syncookies not found")
+	}
+
+	// Check tcp.receive_queue.events (Sum)
+	m = findMetric("tcp.receive_queue.events")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("tcp.receive_queue.events is not Sum[int64], got %T", m.Data)
+		} else {
+			foundOFO := false
+			for _, dp := range sum.DataPoints {
+				event, _ := dp.Attributes.Value("event")
+				if event.AsString() == "out_of_order" {
+					if dp.Value != 42 {
+						t.Errorf("tcp out of order queue events = %d, want 42", dp.Value)
+					}
+					foundOFO = true
+				}
+			}
+			if !foundOFO {
+				t.Error("tcp out of order queue data point not found")
+			}
+		}
+	} else {
+		t.Error("tcp.receive_queue.events not found")
+	}
+}
@@ -0,0 +1,269 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// tcpInfoStates is the sock_diag state mask for connections with meaningful tcp_info;
+// listening, time-wait and request sockets are excluded.
+const tcpInfoStates = 1<<0x01 | 1<<0x02 | 1<<0x04 | 1<<0x05 | 1<<0x07 | 1<<0x08 | 1<<0x09 | 1<<0x0B
+
+// TCPInfo collector exposes distributions of per-connection TCP statistics (struct
+// tcp_info) read over netlink sock_diag.
+//
+// OpenTelemetry has no asynchronous histogram, so connections are sampled on an
+// interval and recorded into synchronous histograms.
+type TCPInfo struct {
+	meter    metric.Meter
+	interval time.Duration
+
+	// previous holds the retransmitted segments of every connection at the last sample,
+	// by socket cookie.
+	previous map[uint64]uint32
+	// seeded is set once the first sample has been taken.
+	seeded bool
+}
+
+// TCPInfoOption configures the TCPInfo collector.
+type TCPInfoOption func(*TCPInfo) error
+
+// WithTCPInfoInterval sets how often connections are sampled (defaults to 60s).
+func WithTCPInfoInterval(d time.Duration) TCPInfoOption {
+	return func(c *TCPInfo) error {
+		if d <= 0 {
+			return errors.New("interval must be positive")
+		}
+		c.interval = d
+		return nil
+	}
+}
+
+// NewTCPInfo creates a new TCPInfo collector.
+func NewTCPInfo(opts ...TCPInfoOption) (*TCPInfo, error) {
+	c := &TCPInfo{
+		meter:    otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		interval: 60 * time.Second,
+		previous: make(map[uint64]uint32),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// tcpInfoKey is the attributes a connection is recorded under.
+type tcpInfoKey struct {
+	version    string
+	congestion string
+}
+
+// tcpInfoConnection is a connection read by a sample.
+type tcpInfoConnection struct {
+	key    tcpInfoKey
+	cookie uint64
+	sample tcpInfoSample
+}
+
+// tcpInfoSample holds the fields of struct tcp_info (linux/tcp.h) that are reported.
+type tcpInfoSample struct {
+	rtt          uint32 // usec
+	rttVar       uint32 // usec
+	sndCwnd      uint32 // segments
+	totalRetrans uint32
+	deliveryRate uint64 // bytes per second, 0 if unsupported by the kernel
+}
+
+// parseTCPInfo reads a struct tcp_info. Older kernels send a shorter struct, so
+// only the fields that are present are read.
+func parseTCPInfo(b []byte) (tcpInfoSample, error) {
+	var s tcpInfoSample
+	if len(b) < 104 {
+		return s, fmt.Errorf("tcp_info too short: %d bytes", len(b))
+	}
+
+	native := nl.NativeEndian()
+	s.rtt = native.Uint32(b[68:72])
+	s.rttVar = native.Uint32(b[72:76])
+	s.sndCwnd = native.Uint32(b[80:84])
+	s.totalRetrans = native.Uint32(b[100:104])
+	if len(b) >= 168 {
+		s.deliveryRate = native.Uint64(b[160:168])
+	}
+	return s, nil
+}
+
+// Start registers the TCPInfo histograms and starts sampling connections until ctx is done.
+func (c *TCPInfo) Start(ctx context.Context) error {
+	rtt, err := c.meter.Float64Histogram(
+		"tcp.rtt",
+		metric.WithDescription("Smoothed round trip time of TCP connections"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5),
+	)
+	if err != nil {
+		return err
+	}
+
+	rttVar, err := c.meter.Float64Histogram(
+		"tcp.rtt.variance",
+		metric.WithDescription("Round trip time variance of TCP connections"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5),
+	)
+	if err != nil {
+		return err
+	}
+
+	cwnd, err := c.meter.Int64Histogram(
+		"tcp.congestion_window",
+		metric.WithDescription("Congestion window of TCP connections"),
+		metric.WithUnit("{segment}"),
+		metric.WithExplicitBucketBoundaries(1, 2, 4, 8, 10, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096),
+	)
+	if err != nil {
+		return err
+	}
+
+	deliveryRate, err := c.meter.Int64Histogram(
+		"tcp.delivery_rate",
+		metric.WithDescription("Most recent delivery rate of TCP connections"),
+		metric.WithUnit("By/s"),
+		metric.WithExplicitBucketBoundaries(1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10),
+	)
+	if err != nil {
+		return err
+	}
+
+	retransmits, err := c.meter.Int64Histogram(
+		"tcp.connection.retransmits",
+		metric.WithDescription("Segments retransmitted per TCP connection since the previous sample"),
+		metric.WithUnit("{segment}"),
+		metric.WithExplicitBucketBoundaries(0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000),
+	)
+	if err != nil {
+		return err
+	}
+
+	record := func(ctx context.Context) error {
+		conns, err := c.sample()
+		if err != nil {
+			return err
+		}
+
+		retransmitted := c.retransmitted(conns)
+		for i, conn := range conns {
+			attrs := metric.WithAttributes(
+				attribute.String("ip.version", conn.key.version),
+				attribute.String("congestion_control", conn.key.congestion),
+			)
+			s := conn.sample
+			rtt.Record(ctx, float64(s.rtt)/1e6, attrs)
+			rttVar.Record(ctx, float64(s.rttVar)/1e6, attrs)
+			cwnd.Record(ctx, int64(s.sndCwnd), attrs)
+			if s.deliveryRate > 0 {
+				deliveryRate.Record(ctx, int64(s.deliveryRate), attrs)
+			}
+			if retransmitted != nil {
+				retransmits.Record(ctx, int64(retransmitted[i]), attrs)
+			}
+		}
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := record(ctx); err != nil {
+				otel.Handle(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// sample reads every connection.
+func (c *TCPInfo) sample() ([]tcpInfoConnection, error) {
+	var conns []tcpInfoConnection
+	for _, f := range []struct {
+		family  uint8
+		version string
+	}{{unix.AF_INET, "4"}, {unix.AF_INET6, "6"}} {
+		version := f.version
+		err := dumpInetDiag(f.family, unix.IPPROTO_TCP, 1<<(netlink.INET_DIAG_INFO-1)|1<<(netlink.INET_DIAG_CONG-1), tcpInfoStates, func(s *inetDiagSocket) {
+			var info []byte
+			congestion := "unknown"
+			for _, a := range s.Attrs {
+				switch a.Attr.Type {
+				case netlink.INET_DIAG_INFO:
+					info = a.Value
+				case netlink.INET_DIAG_CONG:
+					congestion = unix.ByteSliceToString(a.Value)
+				}
+			}
+
+			sample, err := parseTCPInfo(info)
+			if err != nil {
+				return
+			}
+			conns = append(conns, tcpInfoConnection{
+				key:    tcpInfoKey{version: version, congestion: congestion},
+				cookie: s.Cookie,
+				sample: sample,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump tcp sockets: %w", err)
+		}
+	}
+	return conns, nil
+}
+
+// retransmitted returns the segments each connection retransmitted since the previous
+// sample, or nil for the first sample, which only records them.
+func (c *TCPInfo) retransmitted(conns []tcpInfoConnection) []uint32 {
+	current := make(map[uint64]uint32, len(conns))
+	var retransmitted []uint32
+	if c.seeded {
+		retransmitted = make([]uint32, len(conns))
+	}
+
+	for i, conn := range conns {
+		current[conn.cookie] = conn.sample.totalRetrans
+		if retransmitted == nil {
+			continue
+		}
+		// A connection that is new since the previous sample has all of its retransmits
+		// counted.
+		retransmitted[i] = conn.sample.totalRetrans
+		if prev, ok := c.previous[conn.cookie]; ok && prev <= conn.sample.totalRetrans {
+			retransmitted[i] -= prev
+		}
+	}
+
+	c.previous = current
+	c.seeded = true
+	return retransmitted
+}
@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseInetDiagMsg(t *testing.T) {
+	// inet_diag_msg for 10.0.2.15:22 in ESTABLISHED with rqueue=3, wqueue=7 and a
+	// single INET_DIAG_CONG attribute.
+	msg := make([]byte, sizeofInetDiagMsg)
+	msg[0] = unix.AF_INET
+	msg[1] = 0x01
+	binary.BigEndian.PutUint16(msg[4:6], 22)
+	copy(msg[8:12], []byte{10, 0, 2, 15})
+	nl.NativeEndian().PutUint32(msg[44:48], 0x2a)
+	nl.NativeEndian().PutUint32(msg[48:52], 0x1)
+	nl.NativeEndian().PutUint32(msg[56:60], 3)
+	nl.NativeEndian().PutUint32(msg[60:64], 7)
+	msg = append(msg, nl.NewRtAttr(netlink.INET_DIAG_CONG, nl.ZeroTerminated("cubic")).Serialize()...)
+
+	s, err := parseInetDiagMsg(msg)
+	if err != nil {
+		t.Fatalf("failed to parse inet_diag_msg: %v", err)
+	}
+
+	if s.LocalAddress.String() != "10.0.2.15" || s.LocalPort != 22 {
+		t.Errorf("local = %s:%d, want 10.0.2.15:22", s.LocalAddress, s.LocalPort)
+	}
+	if s.Cookie != 0x10000002a {
+		t.Errorf("cookie = %#x, want 0x10000002a", s.Cookie)
+	}
+	if s.RQueue != 3 || s.WQueue != 7 {
+		t.Errorf("queues = %d/%d, want 3/7", s.RQueue, s.WQueue)
+	}
+	if len(s.Attrs) != 1 || unix.ByteSliceToString(s.Attrs[0].Value) != "cubic" {
+		t.Errorf("attrs = %v, want cubic congestion control", s.Attrs)
+	}
+}
+
+func TestParseTCPInfo(t *testing.T) {
+	b := make([]byte, 168)
+	nl.NativeEndian().PutUint32(b[68:72], 25000) // rtt
+	nl.NativeEndian().PutUint32(b[72:76], 1200)  // rttvar
+	nl.NativeEndian().PutUint32(b[80:84], 10)    // snd_cwnd
+	nl.NativeEndian().PutUint32(b[100:104], 4)   // total_retrans
+	nl.NativeEndian().PutUint64(b[160:168], 1e6) // delivery_rate
+
+	s, err := parseTCPInfo(b)
+	if err != nil {
+		t.Fatalf("failed to parse tcp_info: %v", err)
+	}
+
+	want := tcpInfoSample{rtt: 25000, rttVar: 1200, sndCwnd: 10, totalRetrans: 4, deliveryRate: 1e6}
+	if s != want {
+		t.Errorf("tcp_info = %+v, want %+v", s, want)
+	}
+
+	// Kernels before 4.9 send a shorter struct without delivery_rate.
+	s, err = parseTCPInfo(b[:104])
+	if err != nil {
+		t.Fatalf("failed to parse short tcp_info: %v", err)
+	}
+	if s.deliveryRate != 0 {
+		t.Errorf("short tcp_info delivery rate = %d, want 0", s.deliveryRate)
+	}
+
+	if _, err := parseTCPInfo(b[:50]); err == nil {
+		t.Error("expected error for truncated tcp_info")
+	}
+}
+
+func TestTCPInfoRetransmitted(t *testing.T) {
+	c, err := NewTCPInfo()
+	if err != nil {
+		t.Fatalf("failed to create tcpinfo collector: %v", err)
+	}
+
+	conn := func(cookie uint64, retrans uint32) tcpInfoConnection {
+		return tcpInfoConnection{cookie: cookie, sample: tcpInfoSample{totalRetrans: retrans}}
+	}
+
+	// The first sample has no retransmits, as they are counted since the previous one.
+	if got := c.retransmitted([]tcpInfoConnection{conn(1, 3), conn(2, 100)}); got != nil {
+		t.Errorf("expected no retransmits in the first sample, got %v", got)
+	}
+
+	// Connection 1 retransmitted 2 more segments, 2 did not retransmit, and 3 is new.
+	got := c.retransmitted([]tcpInfoConnection{conn(1, 5), conn(2, 100), conn(3, 7)})
+	if want := []uint32{2, 0, 7}; !slices.Equal(got, want) {
+		t.Errorf("retransmitted = %v, want %v", got, want)
+	}
+
+	// Connection 1 closed and its cookie is not reused.
+	got = c.retransmitted([]tcpInfoConnection{conn(2, 101), conn(3, 7)})
+	if want := []uint32{1, 0}; !slices.Equal(got, want) {
+		t.Errorf("retransmitted = %v, want %v", got, want)
+	}
+}
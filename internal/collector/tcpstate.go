@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// tcpStates maps the kernel TCP state numbers (include/net/tcp_states.h) to names.
+var tcpStates = map[uint8]string{
+	0x01: "established",
+	0x02: "syn_sent",
+	0x03: "syn_recv",
+	0x04: "fin_wait1",
+	0x05: "fin_wait2",
+	0x06: "time_wait",
+	0x07: "close",
+	0x08: "close_wait",
+	0x09: "last_ack",
+	0x0A: "listen",
+	0x0B: "closing",
+	0x0C: "new_syn_recv",
+}
+
+// TCPState collector exposes TCP sockets broken down by state.
+type TCPState struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	ports          map[uint16]bool
+}
+
+// TCPStateOption configures the TCPState collector.
+type TCPStateOption func(*TCPState) error
+
+// WithTCPStatePorts reports sockets bound to the given local ports under their own
+// "local.port" attribute. Sockets on any other port are aggregated as "other".
+func WithTCPStatePorts(ports ...int) TCPStateOption {
+	return func(c *TCPState) error {
+		for _, p := range ports {
+			if p <= 0 || p > 65535 {
+				return fmt.Errorf("invalid port: %d", p)
+			}
+			c.ports[uint16(p)] = true
+		}
+		return nil
+	}
+}
+
+// NewTCPState creates a new TCPState collector.
+func NewTCPState(procMountPoint string, opts ...TCPStateOption) (*TCPState, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	c := &TCPState{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+		ports:          make(map[uint16]bool),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// tcpStateKey identifies a group of sockets reported together.
+type tcpStateKey struct {
+	state string
+	port  string
+}
+
+// tcpStateValue holds the aggregated values for a group of sockets.
+type tcpStateValue struct {
+	sockets int64
+	txQueue int64
+	rxQueue int64
+}
+
+// Start registers the TCPState metrics callbacks.
+func (c *TCPState) Start(ctx context.Context) error {
+	sockets, err := c.meter.Int64ObservableGauge(
+		"tcp.sockets",
+		metric.WithDescription("TCP sockets by state"),
+		metric.WithUnit("{socket}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	queue, err := c.meter.Int64ObservableGauge(
+		"tcp.sockets.queue",
+		metric.WithDescription("Bytes queued on TCP sockets by state"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		groups, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read tcp sockets: %w", err)
+		}
+
+		for key, v := range groups {
+			attrs := []attribute.KeyValue{attribute.String("state", key.state)}
+			if key.port != "" {
+				attrs = append(attrs, attribute.String("local.port", key.port))
+			}
+
+			o.ObserveInt64(sockets, v.sockets, metric.WithAttributes(attrs...))
+			o.ObserveInt64(queue, v.txQueue, metric.WithAttributes(append(attrs, attribute.String("direction", "transmit"))...))
+			o.ObserveInt64(queue, v.rxQueue, metric.WithAttributes(append(attrs, attribute.String("direction", "receive"))...))
+		}
+
+		return nil
+	}, sockets, queue)
+
+	return err
+}
+
+// read walks /proc/net/tcp and /proc/net/tcp6 and aggregates the sockets by state and port.
+func (c *TCPState) read() (map[tcpStateKey]*tcpStateValue, error) {
+	// When ports are configured, every socket carries a port; unmatched ones are "other".
+	defaultPort := ""
+	if len(c.ports) > 0 {
+		defaultPort = "other"
+	}
+
+	// Report every state, even when no sockets are in it.
+	groups := make(map[tcpStateKey]*tcpStateValue)
+	for _, state := range tcpStates {
+		groups[tcpStateKey{state: state, port: defaultPort}] = &tcpStateValue{}
+	}
+
+	var found bool
+	for _, file := range []string{"/net/tcp", "/net/tcp6"} {
+		err := scanNetSockets(c.procMountPoint+file, func(s netSocket) {
+			state, ok := tcpStates[s.State]
+			if !ok {
+				return
+			}
+
+			key := tcpStateKey{state: state, port: defaultPort}
+			if c.ports[s.LocalPort] {
+				key.port = strconv.Itoa(int(s.LocalPort))
+			}
+
+			v, ok := groups[key]
+			if !ok {
+				v = &tcpStateValue{}
+				groups[key] = v
+			}
+			v.sockets++
+			v.txQueue += int64(s.TxQueue)
+			v.rxQueue += int64(s.RxQueue)
+		})
+		if err != nil {
+			// tcp6 is absent when IPv6 is disabled.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, os.ErrNotExist
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTCPState(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewTCPState(procPath, WithTCPStatePorts(8080))
+	if err != nil {
+		t.Fatalf("failed to create tcpstate collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture tcp + tcp6:
+	// listen: port 22 (v4 + v6), port 8080 (rx_queue=3)
+	// established: 3 sockets on port 22, tx_queue=0x24, rx_queue=0x10
+	// time_wait: 1, close_wait: 1
+
+	// Check tcp.sockets (Gauge)
+	m := findMetric("tcp.sockets")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("tcp.sockets is not Gauge[int64], got %T", m.Data)
+		} else {
+			want := map[[2]string]int64{
+				{"listen", "other"}:      2,
+				{"listen", "8080"}:       1,
+				{"established", "other"}: 3,
+				{"time_wait", "other"}:   1,
+				{"close_wait", "other"}:  1,
+				{"syn_recv", "other"}:    0,
+			}
+			for _, dp := range gauge.DataPoints {
+				state, _ := dp.Attributes.Value("state")
+				port, _ := dp.Attributes.Value("local.port")
+				key := [2]string{state.AsString(), port.AsString()}
+				if v, ok := want[key]; ok {
+					if dp.Value != v {
+						t.Errorf("tcp.sockets %v = %d, want %d", key, dp.Value, v)
+					}
+					delete(want, key)
+				}
+			}
+			if len(want) != 0 {
+				t.Errorf("tcp.sockets data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("tcp.sockets not found")
+	}
+
+	// Check tcp.sockets.queue (Gauge)
+	m = findMetric("tcp.sockets.queue")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("tcp.sockets.queue is not Gauge[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range gauge.DataPoints {
+				state, _ := dp.Attributes.Value("state")
+				dir, _ := dp.Attributes.Value("direction")
+				if state.AsString() == "established" && dir.AsString() == "transmit" {
+					if dp.Value != 36 {
+						t.Errorf("established transmit queue = %d, want 36", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("established transmit queue data point not found")
+			}
+		}
+	} else {
+		t.Error("tcp.sockets.queue not found")
+	}
+}
@@ -49,6 +49,8 @@ func (c *UDP) Start(ctx context.Context) error {
 		return err
 	}
 
+	version := attribute.String("ip.version", "4")
+
 	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
 		snmp, err := readNetSNMP(c.procMountPoint)
 		if err != nil {
@@ -58,20 +60,20 @@ func (c *UDP) Start(ctx context.Context) error {
 		// SNMP UDP Keys: InDatagrams, OutDatagrams, InErrors, NoPorts, RcvbufErrors
 
 		if v, ok := snmp.UDP["InDatagrams"]; ok {
-			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "in"), attribute.String("type", "datagrams")))
+			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "in"), attribute.String("type", "datagrams"), version))
 		}
 		if v, ok := snmp.UDP["OutDatagrams"]; ok {
-			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "out"), attribute.String("type", "datagrams")))
+			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("direction", "out"), attribute.String("type", "datagrams"), version))
 		}
 		if v, ok := snmp.UDP["InErrors"]; ok {
-			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("type", "errors")))
+			o.ObserveInt64(packets, v, metric.WithAttributes(attribute.String("type", "errors"), version))
 		}
 
 		if v, ok := snmp.UDP["NoPorts"]; ok {
-			o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "no_port")))
+			o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "no_port"), version))
 		}
 		if v, ok := snmp.UDP["RcvbufErrors"]; ok {
-			o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "rcv_buf")))
+			o.ObserveInt64(drops, v, metric.WithAttributes(attribute.String("reason", "rcv_buf"), version))
 		}
 
 		return nil
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// UDPSocket collector exposes UDP receive queues and drops per bound port.
+type UDPSocket struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	ports          map[uint16]bool
+
+	// mu guards the drop accounting, which carries state between reads.
+	mu sync.Mutex
+	// previous holds the drops of each socket by inode as of the last read.
+	previous map[uint64]uint64
+	// drops accumulates the drops of the sockets of each port, so that the total
+	// does not fall when a socket closes.
+	drops map[string]int64
+}
+
+// UDPSocketOption configures the UDPSocket collector.
+type UDPSocketOption func(*UDPSocket) error
+
+// WithUDPSocketPorts reports sockets bound to the given local ports under their own
+// "local.port" attribute. Sockets on any other port are aggregated as "other".
+func WithUDPSocketPorts(ports ...int) UDPSocketOption {
+	return func(c *UDPSocket) error {
+		for _, p := range ports {
+			if p <= 0 || p > 65535 {
+				return fmt.Errorf("invalid port: %d", p)
+			}
+			c.ports[uint16(p)] = true
+		}
+		return nil
+	}
+}
+
+// NewUDPSocket creates a new UDPSocket collector.
+func NewUDPSocket(procMountPoint string, opts ...UDPSocketOption) (*UDPSocket, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	c := &UDPSocket{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+		ports:          make(map[uint16]bool),
+		previous:       make(map[uint64]uint64),
+		drops:          map[string]int64{"other": 0},
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// udpSocketValue holds the aggregated values for the sockets of a port.
+type udpSocketValue struct {
+	drops   int64
+	rxQueue int64
+}
+
+// Start registers the UDPSocket metrics callbacks.
+func (c *UDPSocket) Start(ctx context.Context) error {
+	drops, err := c.meter.Int64ObservableCounter(
+		"udp.socket.drops",
+		metric.WithDescription("UDP datagrams dropped by sockets bound to a port"),
+		metric.WithUnit("{datagram}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	rxQueue, err := c.meter.Int64ObservableGauge(
+		"udp.socket.receive_queue",
+		metric.WithDescription("Bytes waiting in the receive queue of sockets bound to a port"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ports, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read udp sockets: %w", err)
+		}
+
+		for port, v := range ports {
+			attrs := metric.WithAttributes(attribute.String("local.port", port))
+			o.ObserveInt64(drops, v.drops, attrs)
+			o.ObserveInt64(rxQueue, v.rxQueue, attrs)
+		}
+
+		return nil
+	}, drops, rxQueue)
+
+	return err
+}
+
+// read walks /proc/net/udp and /proc/net/udp6 and aggregates the sockets by local port.
+// Drops are reported as the running total of the drops each socket accrued since the
+// previous read, so sockets closing between reads do not reset the count.
+func (c *UDPSocket) read() (map[string]*udpSocketValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ports := map[string]*udpSocketValue{"other": {}}
+	current := make(map[uint64]uint64)
+	added := make(map[string]int64)
+
+	var found bool
+	for _, file := range []string{"/net/udp", "/net/udp6"} {
+		err := scanNetSockets(c.procMountPoint+file, func(s netSocket) {
+			port := "other"
+			if c.ports[s.LocalPort] {
+				port = strconv.Itoa(int(s.LocalPort))
+			}
+
+			v, ok := ports[port]
+			if !ok {
+				v = &udpSocketValue{}
+				ports[port] = v
+			}
+
+			// A socket that was not present at the previous read, or whose inode
+			// was reused, accrued all of its drops since then.
+			delta := s.Drops
+			if prev, ok := c.previous[s.Inode]; ok && prev <= s.Drops {
+				delta = s.Drops - prev
+			}
+			added[port] += int64(delta)
+			current[s.Inode] = s.Drops
+			v.rxQueue += int64(s.RxQueue)
+		})
+		if err != nil {
+			// udp6 is absent when IPv6 is disabled.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, os.ErrNotExist
+	}
+
+	c.previous = current
+	for port, delta := range added {
+		c.drops[port] += delta
+	}
+	for port, drops := range c.drops {
+		v, ok := ports[port]
+		if !ok {
+			v = &udpSocketValue{}
+			ports[port] = v
+		}
+		v.drops = drops
+	}
+
+	return ports, nil
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestUDPSocket(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewUDPSocket(procPath, WithUDPSocketPorts(53))
+	if err != nil {
+		t.Fatalf("failed to create udpsocket collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture udp + udp6:
+	// port 53: drops=12+5, rx_queue=0x200
+	// other (514, 68, 59633): drops=40+0+3, rx_queue=0xA00+0x100
+
+	// Check udp.socket.drops (Sum)
+	m := findMetric("udp.socket.drops")
+	if m.Name != "" {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Errorf("udp.socket.drops is not Sum[int64], got %T", m.Data)
+		} else {
+			want := map[string]int64{"53": 17, "other": 43}
+			for _, dp := range sum.DataPoints {
+				port, _ := dp.Attributes.Value("local.port")
+				if dp.Value != want[port.AsString()] {
+					t.Errorf("udp socket drops on %s = %d, want %d", port.AsString(), dp.Value, want[port.AsString()])
+				}
+				delete(want, port.AsString())
+			}
+			if len(want) != 0 {
+				t.Errorf("udp socket drops data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("udp.socket.drops not found")
+	}
+
+	// Check udp.socket.receive_queue (Gauge)
+	m = findMetric("udp.socket.receive_queue")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("udp.socket.receive_queue is not Gauge[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range gauge.DataPoints {
+				port, _ := dp.Attributes.Value("local.port")
+				if port.AsString() == "other" {
+					if dp.Value != 2816 {
+						t.Errorf("udp other receive queue = %d, want 2816", dp.Value)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Error("udp other receive queue data point not found")
+			}
+		}
+	} else {
+		t.Error("udp.socket.receive_queue not found")
+	}
+}
+
+func TestUDPSocketDropsAfterClose(t *testing.T) {
+	procPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(procPath, "net"), 0o755); err != nil {
+		t.Fatalf("failed to create net directory: %v", err)
+	}
+
+	c, err := NewUDPSocket(procPath, WithUDPSocketPorts(53))
+	if err != nil {
+		t.Fatalf("failed to create udpsocket collector: %v", err)
+	}
+
+	const header = "   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n"
+	samples := []struct {
+		name    string
+		sockets string
+		want    map[string]int64
+	}{
+		{
+			name: "initial",
+			sockets: "  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 31001 2 0000000000000000 12\n" +
+				"  101: 00000000:0202 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 31002 2 0000000000000000 40\n" +
+				"  103: 00000000:E8F1 00000000:0000 07 00000000:00000000 00:00000000 00000000  1000        0 31004 2 0000000000000000 3\n",
+			want: map[string]int64{"53": 12, "other": 43},
+		},
+		{
+			// The socket on 514 closed and one on 68 opened; the remaining sockets
+			// dropped more datagrams.
+			name: "closed",
+			sockets: "  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 31001 2 0000000000000000 15\n" +
+				"  102: 0F02000A:0044 0102000A:0043 01 00000000:00000000 00:00000000 00000000     0        0 31003 2 0000000000000000 2\n" +
+				"  103: 00000000:E8F1 00000000:0000 07 00000000:00000000 00:00000000 00000000  1000        0 31004 2 0000000000000000 4\n",
+			want: map[string]int64{"53": 15, "other": 46},
+		},
+		{
+			// Every socket closed.
+			name:    "empty",
+			sockets: "",
+			want:    map[string]int64{"53": 15, "other": 46},
+		},
+	}
+
+	for _, s := range samples {
+		if err := os.WriteFile(filepath.Join(procPath, "net", "udp"), []byte(header+s.sockets), 0o644); err != nil {
+			t.Fatalf("failed to write udp table: %v", err)
+		}
+
+		ports, err := c.read()
+		if err != nil {
+			t.Fatalf("%s: failed to read udp sockets: %v", s.name, err)
+		}
+
+		for port, want := range s.want {
+			v, ok := ports[port]
+			if !ok {
+				t.Errorf("%s: no drops reported for %s", s.name, port)
+				continue
+			}
+			if v.drops != want {
+				t.Errorf("%s: drops on %s = %d, want %d", s.name, port, v.drops, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// unixSocketTypes maps the socket types in /proc/net/unix to names.
+var unixSocketTypes = map[uint64]string{
+	1: "stream",
+	2: "dgram",
+	5: "seqpacket",
+}
+
+// unixSocketStates maps the socket states (SS_*) in /proc/net/unix to names.
+var unixSocketStates = map[uint64]string{
+	1: "unconnected",
+	2: "connecting",
+	3: "connected",
+	4: "disconnecting",
+}
+
+// unixSocketAcceptCon is __SO_ACCEPTCON, set in the flags of listening sockets.
+const unixSocketAcceptCon = 1 << 16
+
+// UnixSocket collector exposes Unix domain socket statistics.
+type UnixSocket struct {
+	meter          metric.Meter
+	fs             procfs.FS
+	procMountPoint string
+	paths          []string
+}
+
+// UnixSocketOption configures the UnixSocket collector.
+type UnixSocketOption func(*UnixSocket) error
+
+// WithUnixSocketPaths reports the accept queue of listening sockets whose path
+// matches one of the globs (see filepath.Match). Abstract sockets begin with "@".
+func WithUnixSocketPaths(globs ...string) UnixSocketOption {
+	return func(c *UnixSocket) error {
+		for _, g := range globs {
+			if _, err := filepath.Match(g, ""); err != nil {
+				return fmt.Errorf("invalid glob %q: %w", g, err)
+			}
+		}
+		c.paths = append(c.paths, globs...)
+		return nil
+	}
+}
+
+// NewUnixSocket creates a new UnixSocket collector.
+func NewUnixSocket(procMountPoint string, opts ...UnixSocketOption) (*UnixSocket, error) {
+	fs, err := procfs.NewFS(procMountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	c := &UnixSocket{
+		meter:          otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:             fs,
+		procMountPoint: procMountPoint,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// unixSocketKey identifies a group of Unix domain sockets reported together.
+type unixSocketKey struct {
+	socketType string
+	state      string
+}
+
+// Start registers the UnixSocket metrics callbacks.
+func (c *UnixSocket) Start(ctx context.Context) error {
+	sockets, err := c.meter.Int64ObservableGauge(
+		"unix.sockets",
+		metric.WithDescription("Unix domain sockets by type and state"),
+		metric.WithUnit("{socket}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	queueCurrent, err := c.meter.Int64ObservableGauge(
+		"unix.listen.queue.current",
+		metric.WithDescription("Connections waiting in the accept queue of listening Unix domain sockets"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	queueLimit, err := c.meter.Int64ObservableGauge(
+		"unix.listen.queue.limit",
+		metric.WithDescription("Configured backlog of listening Unix domain sockets"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		counts, err := c.readCounts()
+		if err != nil {
+			return fmt.Errorf("failed to read net unix: %w", err)
+		}
+
+		for key, count := range counts {
+			o.ObserveInt64(sockets, count, metric.WithAttributes(
+				attribute.String("type", key.socketType),
+				attribute.String("state", key.state),
+			))
+		}
+
+		if len(c.paths) == 0 {
+			return nil
+		}
+
+		// Queue lengths are not in /proc/net/unix, so listeners are read over sock_diag.
+		// For a listening socket the kernel reports the pending connections as rqueue
+		// and the backlog as wqueue.
+		err = dumpUnixDiag(tcpListenState, func(s *unixDiagSocket) {
+			if !c.matches(s.Path) {
+				return
+			}
+			attrs := metric.WithAttributes(attribute.String("path", s.Path))
+			o.ObserveInt64(queueCurrent, int64(s.RQueue), attrs)
+			o.ObserveInt64(queueLimit, int64(s.WQueue), attrs)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to dump unix sockets: %w", err)
+		}
+
+		return nil
+	}, sockets, queueCurrent, queueLimit)
+
+	return err
+}
+
+// matches reports whether path matches one of the configured globs.
+func (c *UnixSocket) matches(path string) bool {
+	for _, g := range c.paths {
+		if ok, _ := filepath.Match(g, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readCounts streams /proc/net/unix and counts the sockets by type and state.
+// Listening sockets are reported with the "listen" state.
+func (c *UnixSocket) readCounts() (map[unixSocketKey]int64, error) {
+	file, err := os.Open(c.procMountPoint + "/net/unix")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	counts := make(map[unixSocketKey]int64)
+
+	scanner := bufio.NewScanner(file)
+	// Skip the header line.
+	scanner.Scan()
+
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode [Path]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			continue
+		}
+		socketType, err := strconv.ParseUint(fields[4], 16, 16)
+		if err != nil {
+			continue
+		}
+		st, err := strconv.ParseUint(fields[5], 16, 8)
+		if err != nil {
+			continue
+		}
+
+		key := unixSocketKey{
+			socketType: unixSocketTypes[socketType],
+			state:      unixSocketStates[st],
+		}
+		if key.socketType == "" {
+			key.socketType = "unknown"
+		}
+		if key.state == "" {
+			key.state = "unknown"
+		}
+		if flags&unixSocketAcceptCon != 0 {
+			key.state = "listen"
+		}
+		counts[key]++
+	}
+
+	return counts, scanner.Err()
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestUnixSocket(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	// Listen on a socket in a temporary directory so there is a listener to match.
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewUnixSocket(procPath, WithUnixSocketPaths(filepath.Join(dir, "*.sock")))
+	if err != nil {
+		t.Fatalf("failed to create unixsocket collector: %v", err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start collector: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	findMetric := func(name string) metricdata.Metrics {
+		for _, m := range metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+		return metricdata.Metrics{}
+	}
+
+	// Fixture unix: stream listen=2, seqpacket listen=1, stream connected=3,
+	// dgram unconnected=1, dgram connected=1
+
+	// Check unix.sockets (Gauge)
+	m := findMetric("unix.sockets")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("unix.sockets is not Gauge[int64], got %T", m.Data)
+		} else {
+			want := map[[2]string]int64{
+				{"stream", "listen"}:     2,
+				{"seqpacket", "listen"}:  1,
+				{"stream", "connected"}:  3,
+				{"dgram", "unconnected"}: 1,
+				{"dgram", "connected"}:   1,
+			}
+			for _, dp := range gauge.DataPoints {
+				socketType, _ := dp.Attributes.Value("type")
+				state, _ := dp.Attributes.Value("state")
+				key := [2]string{socketType.AsString(), state.AsString()}
+				if dp.Value != want[key] {
+					t.Errorf("unix.sockets %v = %d, want %d", key, dp.Value, want[key])
+				}
+				delete(want, key)
+			}
+			if len(want) != 0 {
+				t.Errorf("unix.sockets data points missing: %v", want)
+			}
+		}
+	} else {
+		t.Error("unix.sockets not found")
+	}
+
+	// Check unix.listen.queue.limit (Gauge)
+	m = findMetric("unix.listen.queue.limit")
+	if m.Name != "" {
+		gauge, ok := m.Data.(metricdata.Gauge[int64])
+		if !ok {
+			t.Errorf("unix.listen.queue.limit is not Gauge[int64], got %T", m.Data)
+		} else {
+			found := false
+			for _, dp := range gauge.DataPoints {
+				p, _ := dp.Attributes.Value("path")
+				if p.AsString() == path {
+					if dp.Value <= 0 {
+						t.Errorf("backlog for %s = %d, want > 0", path, dp.Value)
+					}
+					found = true
+				} else {
+					t.Errorf("unexpected listener %s reported", p.AsString())
+				}
+			}
+			if !found {
+				t.Errorf("listener %s data point not found", path)
+			}
+		}
+	} else {
+		t.Error("unix.listen.queue.limit not found")
+	}
+}
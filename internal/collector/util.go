@@ -2,6 +2,8 @@ package collector
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -16,10 +18,36 @@ func readFileInt(path string) (int64, error) {
 	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
 }
 
-// NetSNMPStats holds TCP and UDP statistics.
+// parseCPUList parses a CPU list such as "0-3,8,10-11" (as in
+// /sys/devices/system/cpu/possible), returning the ids in order.
+func parseCPUList(list string) ([]string, error) {
+	var ids []string
+	for _, r := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(r, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			ids = append(ids, strconv.Itoa(cpu))
+		}
+	}
+	return ids, nil
+}
+
+// NetSNMPStats holds IP, ICMP, TCP and UDP statistics.
 type NetSNMPStats struct {
-	TCP map[string]int64
-	UDP map[string]int64
+	IP      map[string]int64
+	ICMP    map[string]int64
+	ICMPMsg map[string]int64
+	TCP     map[string]int64
+	UDP     map[string]int64
 }
 
 // readNetSNMP reads /proc/net/snmp and parses it.
@@ -33,13 +61,45 @@ func readNetSNMP(procPath string) (*NetSNMPStats, error) {
 	return parseSNMP(file)
 }
 
-func parseSNMP(file *os.File) (*NetSNMPStats, error) {
-	stats := &NetSNMPStats{
-		TCP: make(map[string]int64),
-		UDP: make(map[string]int64),
+func parseSNMP(r io.Reader) (*NetSNMPStats, error) {
+	sections, err := parseSNMPSections(r)
+	if err != nil {
+		return nil, err
 	}
 
-	scanner := bufio.NewScanner(file)
+	section := func(name string) map[string]int64 {
+		if s, ok := sections[name]; ok {
+			return s
+		}
+		return make(map[string]int64)
+	}
+
+	return &NetSNMPStats{
+		IP:      section("Ip"),
+		ICMP:    section("Icmp"),
+		ICMPMsg: section("IcmpMsg"),
+		TCP:     section("Tcp"),
+		UDP:     section("Udp"),
+	}, nil
+}
+
+// readNetNetstat reads /proc/net/netstat and returns its sections (e.g. TcpExt, IpExt).
+func readNetNetstat(procPath string) (map[string]map[string]int64, error) {
+	file, err := os.Open(procPath + "/net/netstat")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSNMPSections(file)
+}
+
+// parseSNMPSections parses the header/value line pairs used by /proc/net/snmp and
+// /proc/net/netstat, returning the values keyed by section (e.g. "Tcp") and then header.
+func parseSNMPSections(r io.Reader) (map[string]map[string]int64, error) {
+	sections := make(map[string]map[string]int64)
+
+	scanner := bufio.NewScanner(r)
 	var lastHeaders []string
 	var lastProto string
 
@@ -68,6 +128,11 @@ func parseSNMP(file *os.File) (*NetSNMPStats, error) {
 			if proto != lastProto {
 				continue // Skip or error?
 			}
+			section, ok := sections[proto]
+			if !ok {
+				section = make(map[string]int64)
+				sections[proto] = section
+			}
 			// Parse values
 			for i, valStr := range parts[1:] {
 				if i >= len(lastHeaders) {
@@ -75,14 +140,143 @@ func parseSNMP(file *os.File) (*NetSNMPStats, error) {
 				}
 				val, err := strconv.ParseInt(valStr, 10, 64)
 				if err == nil {
-					if proto == "Tcp" {
-						stats.TCP[lastHeaders[i]] = val
-					} else if proto == "Udp" {
-						stats.UDP[lastHeaders[i]] = val
-					}
+					section[lastHeaders[i]] = val
 				}
 			}
 		}
 	}
+	return sections, scanner.Err()
+}
+
+// readNetSNMP6 reads /proc/net/snmp6 and parses it.
+func readNetSNMP6(procPath string) (map[string]int64, error) {
+	file, err := os.Open(procPath + "/net/snmp6")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSNMP6(file)
+}
+
+// parseSNMP6 parses the "<key> <value>" lines used by /proc/net/snmp6 and
+// /proc/net/dev_snmp6/<iface>.
+func parseSNMP6(r io.Reader) (map[string]int64, error) {
+	stats := make(map[string]int64)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 2 {
+			continue
+		}
+
+		val, err := strconv.ParseInt(parts[1], 10, 64)
+		if err == nil {
+			stats[parts[0]] = val
+		}
+	}
 	return stats, scanner.Err()
 }
+
+// readDevSNMP6 reads /proc/net/dev_snmp6/<iface> and parses it.
+func readDevSNMP6(procPath, iface string) (map[string]int64, error) {
+	file, err := os.Open(procPath + "/net/dev_snmp6/" + iface)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSNMP6(file)
+}
+
+// netSocket is a single entry of /proc/net/{tcp,tcp6,udp,udp6}.
+type netSocket struct {
+	// LocalAddress is the hex encoded local address, as written by the kernel.
+	LocalAddress string
+	LocalPort    uint16
+	State        uint8
+	TxQueue      uint64
+	RxQueue      uint64
+	Inode        uint64
+	// Drops is only present for UDP sockets.
+	Drops uint64
+}
+
+// scanNetSockets streams the socket table at path, calling fn for each entry, so
+// that hosts with very large socket tables do not need to hold them in memory.
+func scanNetSockets(path string, fn func(netSocket)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Skip the header line.
+	scanner.Scan()
+
+	for scanner.Scan() {
+		s, err := parseNetSocket(scanner.Text())
+		if err != nil {
+			continue
+		}
+		fn(s)
+	}
+	return scanner.Err()
+}
+
+// parseNetSocket parses a line such as:
+//
+//	0: 0100007F:0035 00000000:0000 0A 00000000:00000000 00:00000000 00000000 0 0 12345 ...
+func parseNetSocket(line string) (netSocket, error) {
+	var s netSocket
+
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return s, fmt.Errorf("unexpected socket line: %q", line)
+	}
+
+	addr, port, ok := strings.Cut(fields[1], ":")
+	if !ok {
+		return s, fmt.Errorf("unexpected local address: %q", fields[1])
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return s, err
+	}
+	s.LocalAddress = addr
+	s.LocalPort = uint16(p)
+
+	st, err := strconv.ParseUint(fields[3], 16, 8)
+	if err != nil {
+		return s, err
+	}
+	s.State = uint8(st)
+
+	tx, rx, ok := strings.Cut(fields[4], ":")
+	if !ok {
+		return s, fmt.Errorf("unexpected queue: %q", fields[4])
+	}
+	if s.TxQueue, err = strconv.ParseUint(tx, 16, 64); err != nil {
+		return s, err
+	}
+	if s.RxQueue, err = strconv.ParseUint(rx, 16, 64); err != nil {
+		return s, err
+	}
+
+	if len(fields) > 9 {
+		if i, err := strconv.ParseUint(fields[9], 10, 64); err == nil {
+			s.Inode = i
+		}
+	}
+
+	// UDP tables end with "ref pointer drops".
+	if len(fields) == 13 {
+		if d, err := strconv.ParseUint(fields[12], 10, 64); err == nil {
+			s.Drops = d
+		}
+	}
+
+	return s, nil
+}
@@ -2,18 +2,57 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/prometheus/procfs"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
 )
 
-// Wifi collector exposes wireless interface statistics.
+// wifiEventsMinBackoff and wifiEventsMaxBackoff bound the delay before subscribing again
+// after receiving wifi events failed.
+const (
+	wifiEventsMinBackoff = time.Second
+	wifiEventsMaxBackoff = 5 * time.Minute
+)
+
+// Wifi collector exposes wireless interface statistics. They are read over nl80211,
+// falling back to /proc/net/wireless when nl80211 is not available.
+//
+// With nl80211, it also emits a log record for every connection, disconnection and roam
+// between access points of a managed interface.
 type Wifi struct {
-	meter metric.Meter
-	fs    procfs.FS
+	meter  metric.Meter
+	logger log.Logger
+	fs     procfs.FS
+	// family is the nl80211 generic netlink family, or nil to read /proc/net/wireless.
+	family *netlink.GenlFamily
+	// links holds the association state of managed interfaces by index.
+	links       map[uint32]wifiLinkState
+	roams       metric.Int64Counter
+	disconnects metric.Int64Counter
+}
+
+// wifiLinkState is the association state of a managed interface.
+type wifiLinkState struct {
+	name string
+	// bssid is the access point the interface is associated with, or empty if it is
+	// disconnected.
+	bssid string
+	// previous is the access point of the last connection and disconnectedAt when it was
+	// lost; both are only set while disconnected.
+	previous       string
+	disconnectedAt time.Time
 }
 
 // NewWifi creates a new Wifi collector.
@@ -23,15 +62,46 @@ func NewWifi(procMountPoint string) (*Wifi, error) {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 
+	// nl80211 is missing when cfg80211 is not loaded, e.g. on hosts without wireless.
+	family, err := netlink.GenlFamilyGet(nl80211FamilyName)
+	if err != nil {
+		if !errors.Is(err, unix.ENOENT) {
+			otel.Handle(fmt.Errorf("failed to resolve nl80211, falling back to /proc/net/wireless: %w", err))
+		}
+		family = nil
+	}
+
 	return &Wifi{
-		meter: otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
-		fs:    fs,
+		meter:  otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		logger: global.Logger("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		fs:     fs,
+		family: family,
+		links:  make(map[uint32]wifiLinkState),
 	}, nil
 }
 
-// Start registers the wifi metrics callbacks.
+// wifiMetrics holds the instruments of the Wifi collector.
+type wifiMetrics struct {
+	signal        metric.Float64ObservableGauge
+	quality       metric.Float64ObservableGauge
+	signalAverage metric.Float64ObservableGauge
+	bitrate       metric.Int64ObservableGauge
+	mcs           metric.Int64ObservableGauge
+	frequency     metric.Int64ObservableGauge
+	channelWidth  metric.Int64ObservableGauge
+	retries       metric.Int64ObservableCounter
+	failures      metric.Int64ObservableCounter
+	beaconLosses  metric.Int64ObservableCounter
+	info          metric.Int64ObservableGauge
+}
+
+// Start registers the wifi metrics callbacks and, with nl80211, handles association events
+// until ctx is cancelled.
 func (c *Wifi) Start(ctx context.Context) error {
-	signalGauge, err := c.meter.Float64ObservableGauge(
+	var m wifiMetrics
+	var err error
+
+	m.signal, err = c.meter.Float64ObservableGauge(
 		"wifi.signal",
 		metric.WithDescription("Wifi signal level (dBm)"),
 		metric.WithUnit("dBm"),
@@ -40,7 +110,7 @@ func (c *Wifi) Start(ctx context.Context) error {
 		return err
 	}
 
-	qualityGauge, err := c.meter.Float64ObservableGauge(
+	m.quality, err = c.meter.Float64ObservableGauge(
 		"wifi.quality",
 		metric.WithDescription("Wifi link quality"),
 	)
@@ -48,10 +118,97 @@ func (c *Wifi) Start(ctx context.Context) error {
 		return err
 	}
 
+	m.signalAverage, err = c.meter.Float64ObservableGauge(
+		"wifi.signal.average",
+		metric.WithDescription("Wifi average signal level (dBm)"),
+		metric.WithUnit("dBm"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.bitrate, err = c.meter.Int64ObservableGauge(
+		"wifi.bitrate",
+		metric.WithDescription("Wifi bitrate of the last frame"),
+		metric.WithUnit("bit/s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.mcs, err = c.meter.Int64ObservableGauge(
+		"wifi.mcs",
+		metric.WithDescription("Wifi modulation and coding scheme index of the last frame"),
+		metric.WithUnit("{index}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.frequency, err = c.meter.Int64ObservableGauge(
+		"wifi.frequency",
+		metric.WithDescription("Wifi channel frequency"),
+		metric.WithUnit("MHz"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.channelWidth, err = c.meter.Int64ObservableGauge(
+		"wifi.channel.width",
+		metric.WithDescription("Wifi channel width"),
+		metric.WithUnit("MHz"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.retries, err = c.meter.Int64ObservableCounter(
+		"wifi.transmit.retries",
+		metric.WithDescription("Wifi frame transmission retries"),
+		metric.WithUnit("{retries}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.failures, err = c.meter.Int64ObservableCounter(
+		"wifi.transmit.failures",
+		metric.WithDescription("Wifi frames that failed to transmit"),
+		metric.WithUnit("{frames}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.beaconLosses, err = c.meter.Int64ObservableCounter(
+		"wifi.beacon.losses",
+		metric.WithDescription("Wifi beacons missed from the access point"),
+		metric.WithUnit("{events}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	m.info, err = c.meter.Int64ObservableGauge(
+		"wifi.info",
+		metric.WithDescription("Wifi network the interface is associated with (always 1)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
 	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var nlErr error
+		if c.family != nil {
+			nlErr = c.observeNL80211(o, m)
+		}
+
+		// nl80211 has no link quality, so it is read from /proc/net/wireless either way.
 		stats, err := c.fs.Wireless()
 		if err != nil {
-			return nil
+			return nlErr
 		}
 
 		for _, iface := range stats {
@@ -60,11 +217,358 @@ func (c *Wifi) Start(ctx context.Context) error {
 			// procfs Wireless struct fields:
 			// Name, Status, QualityLink, QualityLevel, QualityNoise...
 
-			o.ObserveFloat64(signalGauge, float64(iface.QualityLevel), attrs)
-			o.ObserveFloat64(qualityGauge, float64(iface.QualityLink), attrs)
+			if c.family == nil {
+				o.ObserveFloat64(m.signal, float64(iface.QualityLevel), attrs)
+			}
+			o.ObserveFloat64(m.quality, float64(iface.QualityLink), attrs)
 		}
+		return nlErr
+	}, m.signal, m.quality, m.signalAverage, m.bitrate, m.mcs, m.frequency, m.channelWidth, m.retries, m.failures, m.beaconLosses, m.info)
+	if err != nil {
+		return err
+	}
+
+	if c.family == nil {
 		return nil
-	}, signalGauge, qualityGauge)
+	}
+
+	c.roams, err = c.meter.Int64Counter(
+		"wifi.roams",
+		metric.WithDescription("Wifi roams from one access point to another without disconnecting"),
+		metric.WithUnit("{roams}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	c.disconnects, err = c.meter.Int64Counter(
+		"wifi.disconnects",
+		metric.WithDescription("Wifi disconnections from an access point"),
+		metric.WithUnit("{disconnects}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.subscribe(ctx)
+}
+
+// observeNL80211 reports the station of every managed (client) wireless interface.
+func (c *Wifi) observeNL80211(o metric.Observer, m wifiMetrics) error {
+	ifaces, err := listWifiInterfaces(c.family.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, ifi := range ifaces {
+		if ifi.Type != unix.NL80211_IFTYPE_STATION {
+			continue
+		}
+		iface := attribute.String("interface", ifi.Name)
+		attrs := metric.WithAttributes(iface)
+
+		if ifi.Frequency != nil {
+			o.ObserveInt64(m.frequency, *ifi.Frequency, attrs)
+		}
+		if ifi.ChannelWidth != nil {
+			o.ObserveInt64(m.channelWidth, *ifi.ChannelWidth, attrs)
+		}
+
+		// A managed interface has a single station, the access point it is associated
+		// with; there is none while it is disconnected. An interface that fails, e.g.
+		// because it was removed since it was listed, does not stop the others.
+		stations, err := listWifiStations(c.family.ID, ifi.Index)
+		if err != nil {
+			otel.Handle(fmt.Errorf("failed to dump wifi stations of %s: %w", ifi.Name, err))
+			continue
+		}
+
+		for _, sta := range stations {
+			observeWifiStation(o, m, iface, ifi, sta)
+		}
+	}
+	return nil
+}
+
+// observeWifiStation reports the values of the station an interface is associated with.
+func observeWifiStation(o metric.Observer, m wifiMetrics, iface attribute.KeyValue, ifi wifiInterface, sta wifiStation) {
+	attrs := metric.WithAttributes(iface)
+	transmit := metric.WithAttributes(iface, attribute.String("direction", "transmit"))
+	receive := metric.WithAttributes(iface, attribute.String("direction", "receive"))
+
+	o.ObserveInt64(m.info, 1, metric.WithAttributes(
+		iface,
+		attribute.String("ssid", ifi.SSID),
+		attribute.String("bssid", sta.BSSID),
+	))
+
+	if sta.Signal != nil {
+		o.ObserveFloat64(m.signal, float64(*sta.Signal), attrs)
+	}
+	if sta.SignalAverage != nil {
+		o.ObserveFloat64(m.signalAverage, float64(*sta.SignalAverage), attrs)
+	}
+	if sta.TxBitrate != nil {
+		o.ObserveInt64(m.bitrate, *sta.TxBitrate, transmit)
+	}
+	if sta.RxBitrate != nil {
+		o.ObserveInt64(m.bitrate, *sta.RxBitrate, receive)
+	}
+	if sta.TxMCS != nil {
+		o.ObserveInt64(m.mcs, *sta.TxMCS, transmit)
+	}
+	if sta.RxMCS != nil {
+		o.ObserveInt64(m.mcs, *sta.RxMCS, receive)
+	}
+	if sta.TxRetries != nil {
+		o.ObserveInt64(m.retries, *sta.TxRetries, attrs)
+	}
+	if sta.TxFailed != nil {
+		o.ObserveInt64(m.failures, *sta.TxFailed, attrs)
+	}
+	if sta.BeaconLoss != nil {
+		o.ObserveInt64(m.beaconLosses, *sta.BeaconLoss, attrs)
+	}
+}
+
+// subscribe joins the nl80211 mlme multicast group and handles its events until ctx is
+// cancelled.
+func (c *Wifi) subscribe(ctx context.Context) error {
+	s, err := c.subscribeEvents()
+	if err != nil {
+		return err
+	}
+
+	go c.run(ctx, s)
+	return nil
+}
+
+// subscribeEvents opens a socket subscribed to the nl80211 mlme events, and seeds the
+// association state after subscribing so no change is missed.
+func (c *Wifi) subscribeEvents() (*nl.NetlinkSocket, error) {
+	s, err := subscribeNL80211(c.family, unix.NL80211_MULTICAST_GROUP_MLME)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to wifi events: %w", err)
+	}
+
+	if err := c.seed(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// seed reads the association state of the managed interfaces. The state of an interface
+// that is still associated with the same access point, or still disconnected, is kept.
+func (c *Wifi) seed() error {
+	ifaces, err := listWifiInterfaces(c.family.ID)
+	if err != nil {
+		return err
+	}
+
+	links := make(map[uint32]wifiLinkState)
+	for _, ifi := range ifaces {
+		if ifi.Type != unix.NL80211_IFTYPE_STATION {
+			continue
+		}
+		state := wifiLinkState{name: ifi.Name}
+		if stations, err := listWifiStations(c.family.ID, ifi.Index); err == nil && len(stations) > 0 {
+			state.bssid = stations[0].BSSID
+		}
+		if prev, ok := c.links[ifi.Index]; ok && prev.bssid == state.bssid {
+			state.previous, state.disconnectedAt = prev.previous, prev.disconnectedAt
+		}
+		links[ifi.Index] = state
+	}
+	c.links = links
+	return nil
+}
+
+// run handles the events of s until ctx is cancelled. When receiving fails, the socket is
+// replaced by a new subscription, retried with an exponential backoff.
+func (c *Wifi) run(ctx context.Context, s *nl.NetlinkSocket) {
+	backoff := wifiEventsMinBackoff
+	for {
+		subscribed := time.Now()
+		err := c.receive(ctx, s)
+		s.Close()
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A subscription that worked for a while starts the backoff over.
+		if time.Since(subscribed) > wifiEventsMaxBackoff {
+			backoff = wifiEventsMinBackoff
+		}
+
+		for {
+			otel.Handle(fmt.Errorf("wifi events unavailable, resubscribing in %s: %w", backoff, err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, wifiEventsMaxBackoff)
+
+			if s, err = c.subscribeEvents(); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// receive handles the events of s until receiving fails or ctx is cancelled. When events
+// were lost because the receive buffer was full, the association state is read again.
+func (c *Wifi) receive(ctx context.Context, s *nl.NetlinkSocket) error {
+	// Closing the socket interrupts the blocked Receive below.
+	stop := context.AfterFunc(ctx, s.Close)
+	defer stop()
+
+	for {
+		msgs, _, err := s.Receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, unix.ENOBUFS) {
+				if err := c.seed(); err != nil {
+					otel.Handle(fmt.Errorf("failed to read wifi association state: %w", err))
+				}
+				continue
+			}
+			return fmt.Errorf("failed to receive wifi events: %w", err)
+		}
+
+		for _, msg := range msgs {
+			cmd, a, err := parseGenlMsg(msg.Data)
+			if err != nil {
+				otel.Handle(err)
+				continue
+			}
+			c.handleEvent(ctx, cmd, a)
+		}
+	}
+}
+
+// handleEvent updates the association state of an interface from an nl80211 event and
+// reports connections, disconnections and roams.
+func (c *Wifi) handleEvent(ctx context.Context, cmd uint8, a nlAttrs) {
+	index, ok := a.uint32(unix.NL80211_ATTR_IFINDEX)
+	if !ok {
+		return
+	}
+
+	prev, ok := c.links[index]
+	if !ok {
+		prev.name = strconv.FormatUint(uint64(index), 10)
+		if ifi, err := net.InterfaceByIndex(int(index)); err == nil {
+			prev.name = ifi.Name
+		}
+	}
+	iface := log.String("interface", prev.name)
+	bssid, _ := a.mac(unix.NL80211_ATTR_MAC)
+
+	switch cmd {
+	case unix.NL80211_CMD_CONNECT:
+		status, _ := a.uint16(unix.NL80211_ATTR_STATUS_CODE)
+		_, timedOut := a[unix.NL80211_ATTR_TIMED_OUT]
+		if status != 0 || timedOut {
+			c.emit(ctx, "wifi.connect.failed", log.SeverityWarn,
+				fmt.Sprintf("interface %s failed to connect to %s (status %d)", prev.name, bssid, status),
+				iface,
+				log.String("bssid", bssid),
+				log.Int("status", int(status)),
+				log.Bool("timed_out", timedOut),
+			)
+			return
+		}
+
+		c.links[index] = wifiLinkState{name: prev.name, bssid: bssid}
+
+		// Reassociating with another access point without disconnecting is a roam; the
+		// kernel reports it as a connection when the supplicant manages it.
+		if prev.bssid != "" {
+			if prev.bssid != bssid {
+				c.roam(ctx, prev, bssid)
+			}
+			return
+		}
+
+		c.connect(ctx, prev, bssid)
+
+	case unix.NL80211_CMD_ROAM:
+		c.links[index] = wifiLinkState{name: prev.name, bssid: bssid}
+
+		// Without a known access point to roam from, e.g. when the association happened
+		// before the subscription, it is reported as a connection.
+		if prev.bssid == "" {
+			c.connect(ctx, prev, bssid)
+			return
+		}
+		c.roam(ctx, prev, bssid)
+
+	case unix.NL80211_CMD_DISCONNECT:
+		if prev.bssid == "" && ok {
+			// Already disconnected, e.g. after a failed connection attempt.
+			return
+		}
+		c.links[index] = wifiLinkState{name: prev.name, previous: prev.bssid, disconnectedAt: time.Now()}
+
+		reason, _ := a.uint16(unix.NL80211_ATTR_REASON_CODE)
+		initiator := "local"
+		if _, byAP := a[unix.NL80211_ATTR_DISCONNECTED_BY_AP]; byAP {
+			initiator = "ap"
+		}
+
+		c.disconnects.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("interface", prev.name),
+			attribute.String("reason", strconv.Itoa(int(reason))),
+		))
+		c.emit(ctx, "wifi.disconnect", log.SeverityWarn,
+			fmt.Sprintf("interface %s disconnected from %s (reason %d, by %s)", prev.name, prev.bssid, reason, initiator),
+			iface,
+			log.String("bssid", prev.bssid),
+			log.Int("reason", int(reason)),
+			log.String("initiator", initiator),
+		)
+	}
+}
+
+// connect reports an interface connecting to an access point, with the duration of the
+// preceding disconnection if it is known.
+func (c *Wifi) connect(ctx context.Context, prev wifiLinkState, bssid string) {
+	attrs := []log.KeyValue{log.String("interface", prev.name), log.String("bssid", bssid)}
+	body := fmt.Sprintf("interface %s connected to %s", prev.name, bssid)
+	if !prev.disconnectedAt.IsZero() {
+		duration := time.Since(prev.disconnectedAt)
+		attrs = append(attrs,
+			log.String("bssid.previous", prev.previous),
+			log.Float64("disconnected.duration", duration.Seconds()),
+		)
+		body = fmt.Sprintf("%s after %s disconnected", body, duration.Round(time.Millisecond))
+	}
+	c.emit(ctx, "wifi.connect", log.SeverityInfo, body, attrs...)
+}
+
+// roam reports an interface moving from one access point to another.
+func (c *Wifi) roam(ctx context.Context, prev wifiLinkState, bssid string) {
+	c.roams.Add(ctx, 1, metric.WithAttributes(attribute.String("interface", prev.name)))
+	c.emit(ctx, "wifi.roam", log.SeverityInfo,
+		fmt.Sprintf("interface %s roamed from %s to %s", prev.name, prev.bssid, bssid),
+		log.String("interface", prev.name),
+		log.String("bssid", bssid),
+		log.String("bssid.previous", prev.bssid),
+	)
+}
 
-	return err
+// emit writes a log record for an event.
+func (c *Wifi) emit(ctx context.Context, event string, severity log.Severity, body string, attrs ...log.KeyValue) {
+	var r log.Record
+	r.SetEventName(event)
+	r.SetTimestamp(time.Now())
+	r.SetSeverity(severity)
+	r.SetBody(log.StringValue(body))
+	r.AddAttributes(attrs...)
+	c.logger.Emit(ctx, r)
 }
@@ -4,10 +4,16 @@ import (
 	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/vishvananda/netlink/nl"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"golang.org/x/sys/unix"
 )
 
 func TestWifi(t *testing.T) {
@@ -20,6 +26,8 @@ func TestWifi(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create wifi collector: %v", err)
 	}
+	// Read the fixture even on hosts with nl80211.
+	c.family = nil
 
 	if err := c.Start(context.Background()); err != nil {
 		t.Fatalf("failed to start collector: %v", err)
@@ -71,3 +79,229 @@ func TestWifi(t *testing.T) {
 		}
 	}
 }
+
+func TestParseWifiInterface(t *testing.T) {
+	var b []byte
+	for _, attr := range []*nl.RtAttr{
+		nl.NewRtAttr(unix.NL80211_ATTR_IFINDEX, nl.Uint32Attr(3)),
+		nl.NewRtAttr(unix.NL80211_ATTR_IFNAME, nl.ZeroTerminated("wlan0")),
+		nl.NewRtAttr(unix.NL80211_ATTR_IFTYPE, nl.Uint32Attr(unix.NL80211_IFTYPE_STATION)),
+		nl.NewRtAttr(unix.NL80211_ATTR_SSID, []byte("home")),
+		nl.NewRtAttr(unix.NL80211_ATTR_WIPHY_FREQ, nl.Uint32Attr(5180)),
+		nl.NewRtAttr(unix.NL80211_ATTR_CHANNEL_WIDTH, nl.Uint32Attr(unix.NL80211_CHAN_WIDTH_80)),
+	} {
+		b = append(b, attr.Serialize()...)
+	}
+
+	a, err := parseNLAttrs(b)
+	if err != nil {
+		t.Fatalf("failed to parse attributes: %v", err)
+	}
+	ifi := parseWifiInterface(a)
+
+	if ifi.Index != 3 || ifi.Name != "wlan0" || ifi.Type != unix.NL80211_IFTYPE_STATION || ifi.SSID != "home" {
+		t.Errorf("unexpected interface %+v", ifi)
+	}
+	if ifi.Frequency == nil || *ifi.Frequency != 5180 {
+		t.Errorf("expected frequency 5180, got %v", ifi.Frequency)
+	}
+	if ifi.ChannelWidth == nil || *ifi.ChannelWidth != 80 {
+		t.Errorf("expected channel width 80, got %v", ifi.ChannelWidth)
+	}
+}
+
+func TestParseWifiStation(t *testing.T) {
+	info := nl.NewRtAttr(unix.NL80211_ATTR_STA_INFO|unix.NLA_F_NESTED, nil)
+	info.AddRtAttr(unix.NL80211_STA_INFO_SIGNAL, []byte{0xc4})     // -60
+	info.AddRtAttr(unix.NL80211_STA_INFO_SIGNAL_AVG, []byte{0xc6}) // -58
+	info.AddRtAttr(unix.NL80211_STA_INFO_TX_RETRIES, nl.Uint32Attr(12))
+	info.AddRtAttr(unix.NL80211_STA_INFO_TX_FAILED, nl.Uint32Attr(2))
+	info.AddRtAttr(unix.NL80211_STA_INFO_BEACON_LOSS, nl.Uint32Attr(1))
+	tx := info.AddRtAttr(unix.NL80211_STA_INFO_TX_BITRATE|unix.NLA_F_NESTED, nil)
+	tx.AddRtAttr(unix.NL80211_RATE_INFO_BITRATE32, nl.Uint32Attr(8667))
+	tx.AddRtAttr(unix.NL80211_RATE_INFO_VHT_MCS, []byte{9})
+	rx := info.AddRtAttr(unix.NL80211_STA_INFO_RX_BITRATE|unix.NLA_F_NESTED, nil)
+	rx.AddRtAttr(unix.NL80211_RATE_INFO_BITRATE, nl.Uint16Attr(540))
+
+	var b []byte
+	b = append(b, nl.NewRtAttr(unix.NL80211_ATTR_MAC, []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}).Serialize()...)
+	b = append(b, info.Serialize()...)
+
+	a, err := parseNLAttrs(b)
+	if err != nil {
+		t.Fatalf("failed to parse attributes: %v", err)
+	}
+	sta, err := parseWifiStation(a)
+	if err != nil {
+		t.Fatalf("failed to parse station: %v", err)
+	}
+
+	if sta.BSSID != "02:00:00:00:00:01" {
+		t.Errorf("expected bssid 02:00:00:00:00:01, got %s", sta.BSSID)
+	}
+
+	want := map[string]struct {
+		got  *int64
+		want int64
+	}{
+		"signal":         {sta.Signal, -60},
+		"signal average": {sta.SignalAverage, -58},
+		"tx bitrate":     {sta.TxBitrate, 866700000},
+		"tx mcs":         {sta.TxMCS, 9},
+		"rx bitrate":     {sta.RxBitrate, 54000000},
+		"tx retries":     {sta.TxRetries, 12},
+		"tx failed":      {sta.TxFailed, 2},
+		"beacon loss":    {sta.BeaconLoss, 1},
+	}
+	for name, v := range want {
+		if v.got == nil || *v.got != v.want {
+			t.Errorf("expected %s %d, got %v", name, v.want, v.got)
+		}
+	}
+	if sta.RxMCS != nil {
+		t.Errorf("expected no rx mcs for a legacy rate, got %d", *sta.RxMCS)
+	}
+}
+
+func TestWifiEvents(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	otel.SetMeterProvider(provider)
+
+	exporter := &recordExporter{}
+	global.SetLoggerProvider(sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter))))
+
+	procPath, _ := filepath.Abs("testdata/proc")
+	c, err := NewWifi(procPath)
+	if err != nil {
+		t.Fatalf("failed to create wifi collector: %v", err)
+	}
+
+	// Drive the handler directly rather than through a subscription, which needs nl80211.
+	c.roams, err = c.meter.Int64Counter("wifi.roams")
+	if err != nil {
+		t.Fatalf("failed to create counter: %v", err)
+	}
+	c.disconnects, err = c.meter.Int64Counter("wifi.disconnects")
+	if err != nil {
+		t.Fatalf("failed to create counter: %v", err)
+	}
+	c.links[3] = wifiLinkState{name: "wlan0", bssid: "02:00:00:00:00:01"}
+
+	ctx := context.Background()
+	event := func(cmd uint8, mac byte, attrs ...*nl.RtAttr) {
+		var b []byte
+		b = append(b, nl.NewRtAttr(unix.NL80211_ATTR_IFINDEX, nl.Uint32Attr(3)).Serialize()...)
+		if mac != 0 {
+			b = append(b, nl.NewRtAttr(unix.NL80211_ATTR_MAC, []byte{0x02, 0, 0, 0, 0, mac}).Serialize()...)
+		}
+		for _, attr := range attrs {
+			b = append(b, attr.Serialize()...)
+		}
+		a, err := parseNLAttrs(b)
+		if err != nil {
+			t.Fatalf("failed to parse attributes: %v", err)
+		}
+		c.handleEvent(ctx, cmd, a)
+	}
+	status := func(code uint16) *nl.RtAttr {
+		return nl.NewRtAttr(unix.NL80211_ATTR_STATUS_CODE, nl.Uint16Attr(code))
+	}
+
+	event(unix.NL80211_CMD_ROAM, 2)               // Roam 01 -> 02
+	event(unix.NL80211_CMD_CONNECT, 3, status(0)) // Reassociation 02 -> 03, a roam
+	// Deauthenticated by the AP
+	event(unix.NL80211_CMD_DISCONNECT, 0,
+		nl.NewRtAttr(unix.NL80211_ATTR_REASON_CODE, nl.Uint16Attr(7)),
+		nl.NewRtAttr(unix.NL80211_ATTR_DISCONNECTED_BY_AP, nil),
+	)
+	event(unix.NL80211_CMD_CONNECT, 4, status(17)) // Rejected
+	event(unix.NL80211_CMD_DISCONNECT, 0)          // Already disconnected, ignored
+
+	// Pretend the outage has lasted a while.
+	state := c.links[3]
+	state.disconnectedAt = time.Now().Add(-2 * time.Second)
+	c.links[3] = state
+	event(unix.NL80211_CMD_CONNECT, 1, status(0))
+
+	// A roam from an access point that was never seen is a connection.
+	c.links[3] = wifiLinkState{name: "wlan0"}
+	event(unix.NL80211_CMD_ROAM, 2)
+
+	want := []struct {
+		event string
+		attrs map[string]string
+	}{
+		{"wifi.roam", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:02", "bssid.previous": "02:00:00:00:00:01"}},
+		{"wifi.roam", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:03", "bssid.previous": "02:00:00:00:00:02"}},
+		{"wifi.disconnect", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:03", "reason": "7", "initiator": "ap"}},
+		{"wifi.connect.failed", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:04", "status": "17"}},
+		{"wifi.connect", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:01", "bssid.previous": "02:00:00:00:00:03"}},
+		{"wifi.connect", map[string]string{"interface": "wlan0", "bssid": "02:00:00:00:00:02"}},
+	}
+
+	if len(exporter.records) != len(want) {
+		t.Fatalf("expected %d log records, got %d", len(want), len(exporter.records))
+	}
+	for i, w := range want {
+		r := exporter.records[i]
+		if r.EventName() != w.event {
+			t.Errorf("record %d: expected event %s, got %s", i, w.event, r.EventName())
+		}
+		for key, value := range w.attrs {
+			found := false
+			r.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == key {
+					found = true
+					if kv.Value.String() != value {
+						t.Errorf("record %d: expected %s=%s, got %s", i, key, value, kv.Value.String())
+					}
+					return false
+				}
+				return true
+			})
+			if !found {
+				t.Errorf("record %d: attribute %s not found", i, key)
+			}
+		}
+	}
+
+	// The reconnection reports how long the interface was disconnected.
+	exporter.records[4].WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == "disconnected.duration" {
+			if kv.Value.AsFloat64() < 2 {
+				t.Errorf("expected a disconnection of at least 2s, got %f", kv.Value.AsFloat64())
+			}
+			return false
+		}
+		return true
+	})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	if len(rm.ScopeMetrics) == 0 {
+		t.Fatal("no scope metrics found")
+	}
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok || len(sum.DataPoints) != 1 {
+			t.Errorf("expected a single %s data point, got %v", m.Name, m.Data)
+			continue
+		}
+		dp := sum.DataPoints[0]
+		switch m.Name {
+		case "wifi.roams":
+			if dp.Value != 2 {
+				t.Errorf("expected 2 roams, got %d", dp.Value)
+			}
+		case "wifi.disconnects":
+			reason, _ := dp.Attributes.Value("reason")
+			if dp.Value != 1 || reason.AsString() != "7" {
+				t.Errorf("expected 1 disconnect for reason 7, got %d for %s", dp.Value, reason.AsString())
+			}
+		}
+	}
+}
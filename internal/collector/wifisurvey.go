@@ -0,0 +1,306 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+)
+
+// WifiSurvey collector exposes the radio environment of wireless interfaces: the channel
+// survey (how busy each channel is) and the access points visible in scan results.
+//
+// Scanning takes the radio off channel for a moment, so the collector is opt-in.
+type WifiSurvey struct {
+	meter        metric.Meter
+	family       *netlink.GenlFamily
+	scanInterval time.Duration
+}
+
+// WifiSurveyOption configures the WifiSurvey collector.
+type WifiSurveyOption func(*WifiSurvey) error
+
+// WithWifiSurveyScanInterval sets how often managed interfaces are asked to scan (defaults
+// to 5m). Zero disables scanning; the results of scans requested by others (such as
+// wpa_supplicant) are still reported.
+func WithWifiSurveyScanInterval(d time.Duration) WifiSurveyOption {
+	return func(c *WifiSurvey) error {
+		if d < 0 {
+			return errors.New("scan interval must not be negative")
+		}
+		c.scanInterval = d
+		return nil
+	}
+}
+
+// NewWifiSurvey creates a new WifiSurvey collector.
+func NewWifiSurvey(opts ...WifiSurveyOption) (*WifiSurvey, error) {
+	family, err := netlink.GenlFamilyGet(nl80211FamilyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nl80211: %w", err)
+	}
+
+	c := &WifiSurvey{
+		meter:        otel.Meter("github.com/andrewhowdencom/otlp.network/internal/collector"),
+		family:       family,
+		scanInterval: 5 * time.Minute,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// wifiSurvey is the survey of a channel returned by NL80211_CMD_GET_SURVEY.
+type wifiSurvey struct {
+	Frequency uint32 // MHz
+	Noise     *int64 // dBm
+	// Times holds the time in ms the radio spent on the channel by state, e.g. "busy".
+	Times map[string]int64
+}
+
+// wifiSurveyTimes maps the NL80211_SURVEY_INFO_TIME_* attributes to their state.
+var wifiSurveyTimes = map[uint16]string{
+	unix.NL80211_SURVEY_INFO_TIME:      "active",
+	unix.NL80211_SURVEY_INFO_TIME_BUSY: "busy",
+	unix.NL80211_SURVEY_INFO_TIME_RX:   "receive",
+	unix.NL80211_SURVEY_INFO_TIME_TX:   "transmit",
+}
+
+// parseWifiSurvey parses the attributes of an NL80211_CMD_GET_SURVEY message.
+func parseWifiSurvey(a nlAttrs) (wifiSurvey, error) {
+	info, ok := a.nested(unix.NL80211_ATTR_SURVEY_INFO)
+	if !ok {
+		return wifiSurvey{}, errors.New("survey has no info")
+	}
+
+	var s wifiSurvey
+	if s.Frequency, ok = info.uint32(unix.NL80211_SURVEY_INFO_FREQUENCY); !ok {
+		return s, errors.New("survey has no frequency")
+	}
+	if v, ok := info.int8(unix.NL80211_SURVEY_INFO_NOISE); ok {
+		noise := int64(v)
+		s.Noise = &noise
+	}
+
+	s.Times = make(map[string]int64, len(wifiSurveyTimes))
+	for t, state := range wifiSurveyTimes {
+		if v, ok := info.uint64(t); ok {
+			s.Times[state] = int64(v)
+		}
+	}
+	return s, nil
+}
+
+// wifiBSS is an access point (BSS) returned by NL80211_CMD_GET_SCAN.
+type wifiBSS struct {
+	BSSID     string
+	Frequency uint32   // MHz
+	Signal    *float64 // dBm
+	// Associated is set for the BSS the interface is associated with.
+	Associated bool
+}
+
+// parseWifiBSS parses the attributes of an NL80211_CMD_GET_SCAN message.
+func parseWifiBSS(a nlAttrs) (wifiBSS, error) {
+	info, ok := a.nested(unix.NL80211_ATTR_BSS)
+	if !ok {
+		return wifiBSS{}, errors.New("scan result has no bss")
+	}
+
+	var bss wifiBSS
+	bss.BSSID, _ = info.mac(unix.NL80211_BSS_BSSID)
+	if bss.Frequency, ok = info.uint32(unix.NL80211_BSS_FREQUENCY); !ok {
+		return bss, fmt.Errorf("bss %s has no frequency", bss.BSSID)
+	}
+	// The signal is in mBm (1/100 dBm) on drivers that report dBm.
+	if v, ok := info.int32(unix.NL80211_BSS_SIGNAL_MBM); ok {
+		signal := float64(v) / 100
+		bss.Signal = &signal
+	}
+	if v, ok := info.uint32(unix.NL80211_BSS_STATUS); ok {
+		bss.Associated = v == unix.NL80211_BSS_STATUS_ASSOCIATED
+	}
+	return bss, nil
+}
+
+// wifiScanChannel summarises the scan results of a channel.
+type wifiScanChannel struct {
+	count int64
+	// strongest is the signal of the strongest neighbour, i.e. BSS other than the one the
+	// interface is associated with, or nil if there is none.
+	strongest *float64
+}
+
+// summarizeWifiScan groups scan results by frequency.
+func summarizeWifiScan(bsses []wifiBSS) map[uint32]*wifiScanChannel {
+	channels := make(map[uint32]*wifiScanChannel)
+	for _, bss := range bsses {
+		ch, ok := channels[bss.Frequency]
+		if !ok {
+			ch = &wifiScanChannel{}
+			channels[bss.Frequency] = ch
+		}
+		ch.count++
+
+		if bss.Associated || bss.Signal == nil {
+			continue
+		}
+		if ch.strongest == nil || *bss.Signal > *ch.strongest {
+			signal := *bss.Signal
+			ch.strongest = &signal
+		}
+	}
+	return channels
+}
+
+// Start registers the WifiSurvey metrics callbacks and, if enabled, starts scanning until
+// ctx is done.
+func (c *WifiSurvey) Start(ctx context.Context) error {
+	timeMetric, err := c.meter.Int64ObservableCounter(
+		"wifi.channel.time",
+		metric.WithDescription("Time the radio spent on a channel: in total (active), sensing it busy, receiving and transmitting"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return err
+	}
+
+	noiseMetric, err := c.meter.Int64ObservableGauge(
+		"wifi.channel.noise",
+		metric.WithDescription("Noise floor of a channel"),
+		metric.WithUnit("dBm"),
+	)
+	if err != nil {
+		return err
+	}
+
+	bssMetric, err := c.meter.Int64ObservableGauge(
+		"wifi.scan.bss",
+		metric.WithDescription("Access points (BSSes) visible on a channel in the scan results"),
+		metric.WithUnit("{bss}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	signalMetric, err := c.meter.Float64ObservableGauge(
+		"wifi.scan.signal.max",
+		metric.WithDescription("Signal level of the strongest neighbouring access point on a channel"),
+		metric.WithUnit("dBm"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ifaces, err := listWifiInterfaces(c.family.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, ifi := range ifaces {
+			iface := attribute.String("interface", ifi.Name)
+			selector := []*nl.RtAttr{nl.NewRtAttr(unix.NL80211_ATTR_IFINDEX, nl.Uint32Attr(ifi.Index))}
+
+			// Both dumps fail for interfaces that are down; skip those.
+			var surveys []wifiSurvey
+			err := dumpNL80211(c.family.ID, unix.NL80211_CMD_GET_SURVEY, selector, func(a nlAttrs) {
+				if s, err := parseWifiSurvey(a); err == nil {
+					surveys = append(surveys, s)
+				}
+			})
+			if err == nil {
+				for _, s := range surveys {
+					frequency := attribute.String("frequency", strconv.FormatUint(uint64(s.Frequency), 10))
+					for state, v := range s.Times {
+						o.ObserveInt64(timeMetric, v, metric.WithAttributes(iface, frequency, attribute.String("state", state)))
+					}
+					if s.Noise != nil {
+						o.ObserveInt64(noiseMetric, *s.Noise, metric.WithAttributes(iface, frequency))
+					}
+				}
+			}
+
+			var bsses []wifiBSS
+			err = dumpNL80211(c.family.ID, unix.NL80211_CMD_GET_SCAN, selector, func(a nlAttrs) {
+				if bss, err := parseWifiBSS(a); err == nil {
+					bsses = append(bsses, bss)
+				}
+			})
+			if err == nil {
+				for freq, ch := range summarizeWifiScan(bsses) {
+					attrs := metric.WithAttributes(iface, attribute.String("frequency", strconv.FormatUint(uint64(freq), 10)))
+					o.ObserveInt64(bssMetric, ch.count, attrs)
+					if ch.strongest != nil {
+						o.ObserveFloat64(signalMetric, *ch.strongest, attrs)
+					}
+				}
+			}
+		}
+		return nil
+	}, timeMetric, noiseMetric, bssMetric, signalMetric)
+	if err != nil {
+		return err
+	}
+
+	if c.scanInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(c.scanInterval)
+			defer ticker.Stop()
+
+			for {
+				if err := c.scan(); err != nil {
+					otel.Handle(err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// scan asks every managed (client) interface to scan. The results are read from the
+// kernel's cache on collection.
+func (c *WifiSurvey) scan() error {
+	ifaces, err := listWifiInterfaces(c.family.ID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, ifi := range ifaces {
+		if ifi.Type != unix.NL80211_IFTYPE_STATION {
+			continue
+		}
+		err := requestNL80211(c.family.ID, unix.NL80211_CMD_TRIGGER_SCAN, []*nl.RtAttr{
+			nl.NewRtAttr(unix.NL80211_ATTR_IFINDEX, nl.Uint32Attr(ifi.Index)),
+		})
+		// A scan is already running (EBUSY) or the interface is down (ENETDOWN).
+		if errors.Is(err, unix.EBUSY) || errors.Is(err, unix.ENETDOWN) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to trigger scan on %s: %w", ifi.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
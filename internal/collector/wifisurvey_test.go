@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseWifiSurvey(t *testing.T) {
+	info := nl.NewRtAttr(unix.NL80211_ATTR_SURVEY_INFO|unix.NLA_F_NESTED, nil)
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_FREQUENCY, nl.Uint32Attr(2412))
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_NOISE, []byte{0xa1}) // -95
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_TIME, nl.Uint64Attr(1000))
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_TIME_BUSY, nl.Uint64Attr(400))
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_TIME_RX, nl.Uint64Attr(300))
+	info.AddRtAttr(unix.NL80211_SURVEY_INFO_TIME_TX, nl.Uint64Attr(50))
+
+	a, err := parseNLAttrs(info.Serialize())
+	if err != nil {
+		t.Fatalf("failed to parse attributes: %v", err)
+	}
+	s, err := parseWifiSurvey(a)
+	if err != nil {
+		t.Fatalf("failed to parse survey: %v", err)
+	}
+
+	if s.Frequency != 2412 {
+		t.Errorf("expected frequency 2412, got %d", s.Frequency)
+	}
+	if s.Noise == nil || *s.Noise != -95 {
+		t.Errorf("expected noise -95, got %v", s.Noise)
+	}
+	for state, want := range map[string]int64{"active": 1000, "busy": 400, "receive": 300, "transmit": 50} {
+		if s.Times[state] != want {
+			t.Errorf("expected %s time %d, got %d", state, want, s.Times[state])
+		}
+	}
+}
+
+func TestWifiScan(t *testing.T) {
+	bss := func(mac byte, freq uint32, mbm int32, status *uint32) []byte {
+		info := nl.NewRtAttr(unix.NL80211_ATTR_BSS|unix.NLA_F_NESTED, nil)
+		info.AddRtAttr(unix.NL80211_BSS_BSSID, []byte{0x02, 0, 0, 0, 0, mac})
+		info.AddRtAttr(unix.NL80211_BSS_FREQUENCY, nl.Uint32Attr(freq))
+		info.AddRtAttr(unix.NL80211_BSS_SIGNAL_MBM, nl.Uint32Attr(uint32(mbm)))
+		if status != nil {
+			info.AddRtAttr(unix.NL80211_BSS_STATUS, nl.Uint32Attr(*status))
+		}
+		return info.Serialize()
+	}
+	associated := uint32(unix.NL80211_BSS_STATUS_ASSOCIATED)
+
+	var bsses []wifiBSS
+	for _, b := range [][]byte{
+		bss(1, 2412, -4000, &associated), // the access point in use
+		bss(2, 2412, -6500, nil),
+		bss(3, 2412, -5500, nil),
+		bss(4, 5180, -7000, nil),
+	} {
+		a, err := parseNLAttrs(b)
+		if err != nil {
+			t.Fatalf("failed to parse attributes: %v", err)
+		}
+		parsed, err := parseWifiBSS(a)
+		if err != nil {
+			t.Fatalf("failed to parse bss: %v", err)
+		}
+		bsses = append(bsses, parsed)
+	}
+
+	if !bsses[0].Associated || bsses[1].Associated {
+		t.Errorf("expected only the first bss to be associated, got %+v", bsses)
+	}
+	if bsses[0].BSSID != "02:00:00:00:00:01" {
+		t.Errorf("expected bssid 02:00:00:00:00:01, got %s", bsses[0].BSSID)
+	}
+
+	channels := summarizeWifiScan(bsses)
+	if len(channels) != 2 {
+		t.Fatalf("expected 2 channels, got %d", len(channels))
+	}
+
+	// The associated access point is counted, but is not a neighbour.
+	ch := channels[2412]
+	if ch.count != 3 {
+		t.Errorf("expected 3 bsses on 2412, got %d", ch.count)
+	}
+	if ch.strongest == nil || *ch.strongest != -55 {
+		t.Errorf("expected strongest neighbour -55 on 2412, got %v", ch.strongest)
+	}
+
+	ch = channels[5180]
+	if ch.count != 1 || ch.strongest == nil || *ch.strongest != -70 {
+		t.Errorf("expected 1 bss at -70 on 5180, got %d at %v", ch.count, ch.strongest)
+	}
+}
@@ -2,13 +2,17 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
@@ -24,6 +28,7 @@ type Config struct {
 }
 
 // Setup initializes the OpenTelemetry SDK with OTLP HTTP and Prometheus exporters.
+// Logs (such as link events) are only exported when an OTLP endpoint is configured.
 func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
@@ -37,6 +42,7 @@ func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error)
 
 	// 1. OTLP HTTP Exporter (Optional)
 	var readers []sdkmetric.Reader
+	var processors []sdklog.Processor
 
 	if cfg.Endpoint != "" {
 		var otlpOpts []otlpmetrichttp.Option
@@ -50,6 +56,18 @@ func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error)
 			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
 		}
 		readers = append(readers, sdkmetric.NewPeriodicReader(otlpExporter, sdkmetric.WithInterval(cfg.Interval)))
+
+		var logOpts []otlploghttp.Option
+		logOpts = append(logOpts, otlploghttp.WithEndpoint(cfg.Endpoint))
+		if cfg.Insecure {
+			logOpts = append(logOpts, otlploghttp.WithInsecure())
+		}
+
+		logExporter, err := otlploghttp.New(ctx, logOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+		}
+		processors = append(processors, sdklog.NewBatchProcessor(logExporter))
 	}
 
 	// 2. Prometheus Exporter
@@ -69,8 +87,21 @@ func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error)
 
 	meterProvider := sdkmetric.NewMeterProvider(opts...)
 
+	// 4. Logger Provider with processors
+	logOpts := []sdklog.LoggerProviderOption{
+		sdklog.WithResource(res),
+	}
+	for _, p := range processors {
+		logOpts = append(logOpts, sdklog.WithProcessor(p))
+	}
+
+	loggerProvider := sdklog.NewLoggerProvider(logOpts...)
+
 	otel.SetMeterProvider(meterProvider)
+	global.SetLoggerProvider(loggerProvider)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
-	return meterProvider.Shutdown, nil
+	return func(ctx context.Context) error {
+		return errors.Join(meterProvider.Shutdown(ctx), loggerProvider.Shutdown(ctx))
+	}, nil
 }